@@ -3,9 +3,18 @@ package main
 import (
 	"bufio"
 	"container/heap"
+	"encoding/binary"
+	"encoding/csv"
+	"flag"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
 	"os"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -48,6 +57,7 @@ type Player struct {
 	available [5]int // W, V, A, L, D
 	moves     [13]byte
 	moveCount int
+	score     int // punten tot nu toe, voor score-afhankelijke engines
 }
 
 // **engineResult** houdt een engine en zijn totaalscore bij
@@ -101,6 +111,38 @@ func getElementByDepth(prevElement byte, depth int) byte {
 	return elementsDepthArray[prevElement][depth-1]
 }
 
+// **isMoveLetter** geeft aan of een teken een element (W, V, A, L, D) is
+func isMoveLetter(c byte) bool {
+	return c == 'W' || c == 'V' || c == 'A' || c == 'L' || c == 'D'
+}
+
+// **stepTarget** bepaalt het doelelement voor één positie van een diepte- of hybride code.
+// Cijfers 1-9 draaien vanaf de zet van de tegenstander, 'a'-'d' (diepte 1-4) vanaf onze eigen vorige zet.
+func stepTarget(c byte, i int, own, opp *[13]byte) byte {
+	if isMoveLetter(c) {
+		return c // vaste zet uit de opening
+	}
+	if c >= 'a' && c <= 'd' {
+		base := int(c-'a') + 1
+		if i == 0 {
+			return getElementFromCode(base)
+		}
+		return getElementByDepth(own[i-1], base)
+	}
+	depth := int(c - '0')
+	if depth >= 6 {
+		base := depth - 5
+		if i < 2 {
+			return getElementFromCode(base)
+		}
+		return getElementByDepth(opp[i-2], base)
+	}
+	if i == 0 {
+		return getElementFromCode(depth)
+	}
+	return getElementByDepth(opp[i-1], depth)
+}
+
 // **chooseAvailableElement** kiest een beschikbaar element of alternatief met diepte 1 fallback
 func chooseAvailableElement(target byte, available *[5]int) byte {
 	targetIdx := moveToIndexArray[target]
@@ -121,6 +163,133 @@ func chooseAvailableElement(target byte, available *[5]int) byte {
 	return 0
 }
 
+// Fallback-policies: een code kan eindigen op "~p" om te bepalen wat er gebeurt als het doelelement op is.
+// '1'-'3' = blijf roteren met diepte k over alle vier de elementen (daarna D), 'D' = eerst D, 'M' = het element waarvan we het meeste
+// over hebben. Zonder suffix geldt '1', het gedrag van chooseAvailableElement.
+const fallbackPolicyCodes = "123DM"
+
+// **splitPolicy** splitst een code in de basiscode en de fallback-policy (0 = standaard)
+func splitPolicy(engine string) (string, byte) {
+	if n := len(engine); n >= 2 && engine[n-2] == '~' {
+		return engine[:n-2], engine[n-1]
+	}
+	return engine, 0
+}
+
+// **chooseWithPolicy** kiest een beschikbaar element; is het doel op, dan beslist de fallback-policy
+func chooseWithPolicy(target byte, available *[5]int, policy byte) byte {
+	if policy == 0 || policy == '1' {
+		return chooseAvailableElement(target, available)
+	}
+	if target != 0 && available[moveToIndexArray[target]] > 0 {
+		return target
+	}
+	switch policy {
+	case 'D':
+		if available[4] > 0 {
+			return 'D'
+		}
+		return chooseAvailableElement(target, available)
+	case 'M':
+		var best byte
+		for i, c := range depthToElement[:4] {
+			if available[i] > 0 && (best == 0 || available[i] > available[moveToIndexArray[best]]) {
+				best = c
+			}
+		}
+		if best == 0 && available[4] > 0 {
+			best = 'D'
+		}
+		return best
+	}
+	// Roteer telkens met diepte k vanaf het laatst geprobeerde element. Komt de rotatie uit op een element dat al
+	// geprobeerd is (bij k=2 al na één stap: W↔A, V↔L), dan schuift ze één plaats door, zodat alle vier de
+	// elementen aan bod komen voor D.
+	depth := int(policy - '0')
+	var tried [4]bool
+	current := target
+	if current == 'D' || current == 0 {
+		current = 'L' // zoals getElementByDepth telt D als L; L zelf komt dan ook nog aan bod
+	} else {
+		tried[moveToIndexArray[current]] = true
+	}
+	for {
+		next := getElementByDepth(current, depth)
+		for step := 0; step < 4 && tried[moveToIndexArray[next]]; step++ {
+			next = getElementByDepth(next, 1)
+		}
+		if tried[moveToIndexArray[next]] {
+			break
+		}
+		tried[moveToIndexArray[next]] = true
+		if available[moveToIndexArray[next]] > 0 {
+			return next
+		}
+		current = next
+	}
+	if available[4] > 0 {
+		return 'D'
+	}
+	return 0
+}
+
+// Voorraadbewuste engines: een code kan "!vlaggen" dragen (vóór een eventuele "~p"). De zetten zijn openbaar,
+// dus de resterende voorraad van de tegenstander is bekend. 'w' = richt niet op een element waarvan de prooi
+// bij de tegenstander op is, 'v' = kies liever een element dat de tegenstander niet meer kan verslaan.
+const inventoryFlagCodes = "wv"
+
+// **splitInventoryFlags** splitst een code in de basiscode en de voorraadvlaggen
+func splitInventoryFlags(engine string) (string, string) {
+	if idx := strings.IndexByte(engine, '!'); idx >= 0 {
+		return engine[:idx], engine[idx+1:]
+	}
+	return engine, ""
+}
+
+// **preyOf** geeft het element dat door e verslagen wordt (0 voor D)
+func preyOf(e byte) byte {
+	for _, c := range depthToElement[:4] {
+		if determineWinner(e, c) == 1 {
+			return c
+		}
+	}
+	return 0
+}
+
+// **inventoryTarget** past een doelelement aan op de resterende voorraad van de tegenstander
+func inventoryTarget(target byte, flags string, own, opp *[5]int) byte {
+	if target == 0 || target == 'D' {
+		return target
+	}
+	for i := 0; i < len(flags); i++ {
+		switch flags[i] {
+		case 'v':
+			if opp[moveToIndexArray[elementsDepthArray[target][0]]] == 0 {
+				continue // het doel kan al niet meer verslagen worden
+			}
+			for d := 1; d < 4; d++ {
+				c := elementsDepthArray[target][d]
+				if own[moveToIndexArray[c]] > 0 && opp[moveToIndexArray[elementsDepthArray[c][0]]] == 0 {
+					target = c
+					break
+				}
+			}
+		case 'w':
+			if opp[moveToIndexArray[preyOf(target)]] > 0 {
+				continue // het doel kan nog winnen
+			}
+			for d := 0; d < 3; d++ {
+				c := elementsDepthArray[target][d]
+				if own[moveToIndexArray[c]] > 0 && opp[moveToIndexArray[preyOf(c)]] > 0 {
+					target = c
+					break
+				}
+			}
+		}
+	}
+	return target
+}
+
 // **getLastElement** bepaalt het resterende element voor de 13e zet
 func getLastElement(available *[5]int) byte {
 	for i, c := range depthToElement {
@@ -147,34 +316,8 @@ func simulateDepthGame(engine1, engine2 string) (p1Score, p2Score int) {
 	p2.available = [5]int{3, 3, 3, 3, 1}
 
 	for i := 0; i < 12; i++ {
-		depth1 := int(engine1[i] - '0')
-		depth2 := int(engine2[i] - '0')
-
-		var target1, target2 byte
-		if depth1 >= 6 {
-			base1 := depth1 - 5
-			if i < 2 {
-				target1 = getElementFromCode(base1)
-			} else {
-				target1 = getElementByDepth(p2.moves[i-2], base1)
-			}
-		} else if i == 0 {
-			target1 = getElementFromCode(depth1)
-		} else {
-			target1 = getElementByDepth(p2.moves[i-1], depth1)
-		}
-		if depth2 >= 6 {
-			base2 := depth2 - 5
-			if i < 2 {
-				target2 = getElementFromCode(base2)
-			} else {
-				target2 = getElementByDepth(p1.moves[i-2], base2)
-			}
-		} else if i == 0 {
-			target2 = getElementFromCode(depth2)
-		} else {
-			target2 = getElementByDepth(p1.moves[i-1], depth2)
-		}
+		target1 := stepTarget(engine1[i], i, &p1.moves, &p2.moves)
+		target2 := stepTarget(engine2[i], i, &p2.moves, &p1.moves)
 		move1 := chooseAvailableElement(target1, &p1.available)
 		move2 := chooseAvailableElement(target2, &p2.available)
 
@@ -244,207 +387,4685 @@ func simulateFixedGame(engine1, engine2 string) (p1Score, p2Score int) {
 	return p1Score, p2Score
 }
 
-// **generateEngines** genereert alle engine codes met max 1 '5', alle dieptes 1-5
-func generateEngines(startDepth string) []string {
-	var engines []string
-	remainingLength := 12 - len(startDepth)
-	hasFive := strings.Contains(startDepth, "5")
+// **engineTarget** bepaalt het doelelement van een willekeurige engine code voor beurt i (0-11)
+func engineTarget(engine string, i int, own, opp *Player) byte {
+	if isConditionalCode(engine) {
+		engine = conditionalBranch(engine, own.score-opp.score)
+	}
+	if isLookbackCode(engine) {
+		return lookbackTarget(engine, i, &own.moves, &opp.moves)
+	}
+	if isFSMCode(engine) {
+		return fsmTarget(engine, i, &own.moves, &opp.moves)
+	}
+	if len(engine) == 13 {
+		return engine[i]
+	}
+	return stepTarget(engine[i], i, &own.moves, &opp.moves)
+}
 
-	if remainingLength < 0 {
-		return engines
+// **engineMove** bepaalt de werkelijke zet van een engine voor beurt i, rekening houdend met de voorraad
+func engineMove(engine string, i int, own, opp *Player) byte {
+	if len(engine) == 13 {
+		return engine[i] // vaste engines spelen hun zetten ongeacht de voorraad
+	}
+	if i == 12 {
+		return getLastElement(&own.available)
+	}
+	engine, _ = splitEndgame(engine)
+	engine, policy := splitPolicy(engine)
+	engine, flags := splitInventoryFlags(engine)
+	if isOptimalCode(engine) {
+		return optimalMove(engine, i, own, opp) // pas na het strippen, zodat een suffix nooit bij stepTarget belandt
+	}
+	target := engineTarget(engine, i, own, opp)
+	if flags != "" {
+		target = inventoryTarget(target, flags, &own.available, &opp.available)
+	}
+	move := chooseWithPolicy(target, &own.available, policy)
+	if move == 0 {
+		move = getLastElement(&own.available)
+	}
+	return move
+}
+
+// **playMove** legt een zet vast in de staat van een speler
+func playMove(p *Player, move byte) {
+	if isMoveLetter(move) && p.available[moveToIndexArray[move]] > 0 {
+		p.available[moveToIndexArray[move]]--
 	}
+	p.moves[p.moveCount] = move
+	p.moveCount++
+}
 
-	if startDepth != "" {
-		for i, digit := range startDepth {
-			if digit < '1' || digit > '9' {
-				return engines
+// **simulateGame** simuleert een spel tussen twee willekeurige engine codes (trager dan de gespecialiseerde simulaties)
+func simulateGame(engine1, engine2 string) (p1Score, p2Score int) {
+	p1, p2, ok := playGame(engine1, engine2, true)
+	if !ok {
+		return -1, -1
+	}
+	return p1.score, p2.score
+}
+
+// **playGame** speelt een spel tussen twee willekeurige engine codes en geeft de volledige staat van beide
+// spelers terug; met earlyStop stopt het spel zodra p1 niet meer kan winnen of gelijkspelen
+func playGame(engine1, engine2 string, earlyStop bool) (p1, p2 Player, ok bool) {
+	return playGameTraced(engine1, engine2, earlyStop, nil)
+}
+
+// **turnTrace** beschrijft één gespeelde beurt: de staat van beide spelers ervoor en erna, de zetten en of
+// een zet uit een opgelost eindspel kwam
+type turnTrace struct {
+	turn    int
+	before  [2]Player
+	after   [2]Player
+	moves   [2]byte
+	planned [2]bool
+}
+
+// **playGameTraced** is playGame met een optionele callback die na elke beurt wordt aangeroepen
+func playGameTraced(engine1, engine2 string, earlyStop bool, trace func(turnTrace)) (p1, p2 Player, ok bool) {
+	p1.available = [5]int{3, 3, 3, 3, 1}
+	p2.available = [5]int{3, 3, 3, 3, 1}
+	_, solve1 := splitEndgame(engine1)
+	_, solve2 := splitEndgame(engine2)
+	var plan1, plan2 []byte
+
+	for i := 0; i < 13; i++ {
+		var move1, move2 byte
+		var before [2]Player
+		if trace != nil {
+			before = [2]Player{p1, p2}
+		}
+		if solve1 > 0 && i >= 13-solve1 {
+			if i == 13-solve1 {
+				plan1, _ = solveEndgame(p1, p2, engine2, i)
 			}
-			if i == 0 && digit > '5' {
-				return engines // eerste positie mag alleen 1-5 zijn
+			move1 = planMove(plan1, i-(13-solve1), &p1.available)
+		} else {
+			move1 = engineMove(engine1, i, &p1, &p2)
+		}
+		if solve2 > 0 && i >= 13-solve2 {
+			if i == 13-solve2 {
+				plan2, _ = solveEndgame(p2, p1, engine1, i)
 			}
+			move2 = planMove(plan2, i-(13-solve2), &p2.available)
+		} else {
+			move2 = engineMove(engine2, i, &p2, &p1)
 		}
-		generateRemaining(startDepth, remainingLength, hasFive, &engines)
-	} else {
-		for firstDigit := '1'; firstDigit <= '5'; firstDigit++ { // eerste positie: enkel 1-5
-			prefix := string(firstDigit)
-			hasFiveLocal := firstDigit == '5'
-			generateRemaining(prefix, 11, hasFiveLocal, &engines)
+		if move1 == 0 || move2 == 0 {
+			return p1, p2, false
+		}
+		playMove(&p1, move1)
+		playMove(&p2, move2)
+
+		winner := determineWinner(move1, move2)
+		if winner == 1 {
+			p1.score++
+		} else if winner == 2 {
+			p2.score++
+		}
+		if trace != nil {
+			trace(turnTrace{turn: i, before: before, after: [2]Player{p1, p2}, moves: [2]byte{move1, move2},
+				planned: [2]bool{solve1 > 0 && i >= 13-solve1, solve2 > 0 && i >= 13-solve2}})
+		}
+
+		// Early termination zoals in simulateDepthGame, zodat scores van beide simulaties vergelijkbaar blijven
+		if earlyStop && len(engine1) != 13 && len(engine2) != 13 && p2.score-p1.score > 12-i {
+			return p1, p2, true
 		}
 	}
 
-	return engines
+	return p1, p2, true
 }
 
-// **generateRemaining** genereert de resterende posities iteratief
-func generateRemaining(prefix string, remainingLength int, hasUsedFive bool, engines *[]string) {
-	if remainingLength == 0 {
-		if len(prefix) == 12 {
-			*engines = append(*engines, prefix)
+// **describeStep** beschrijft welk deel van de code beurt i bepaalt en vanaf welke zet gerekend wordt
+func describeStep(engine string, i int, own, opp *Player) (token, reference string) {
+	move := func(m byte) string {
+		if m == 0 {
+			return "-"
 		}
-		return
+		return string(m)
 	}
-
-	for digit := '1'; digit <= '9'; digit++ {
-		if digit == '5' && hasUsedFive {
-			continue
+	if len(engine) == 13 {
+		return "vast", "-"
+	}
+	if i == 12 {
+		return "laatste", "-"
+	}
+	if isOptimalCode(engine) {
+		return "optimaal", "-"
+	}
+	engine, _ = splitEndgame(engine)
+	engine, _ = splitPolicy(engine)
+	engine, _ = splitInventoryFlags(engine)
+	if isConditionalCode(engine) {
+		engine = conditionalBranch(engine, own.score-opp.score)
+	}
+	switch {
+	case isLookbackCode(engine):
+		depth, ref := engine[1+2*i], engine[2+2*i]
+		token = string([]byte{depth, ref})
+		if ref == 'e' {
+			if i >= 1 {
+				return token, "eigen " + move(own.moves[i-1])
+			}
+		} else if k := int(ref - '0'); i >= k {
+			return token, fmt.Sprintf("tegen-%d %s", k, move(opp.moves[i-k]))
+		}
+		return token, "-"
+	case isFSMCode(engine):
+		return "fsm", "-"
+	}
+	c := engine[i]
+	switch {
+	case isMoveLetter(c):
+		return string(c), "-"
+	case c >= 'a' && c <= 'd':
+		if i >= 1 {
+			return string(c), "eigen " + move(own.moves[i-1])
+		}
+	case c >= '6' && c <= '9':
+		if i >= 2 {
+			return string(c), "tegen-2 " + move(opp.moves[i-2])
+		}
+	case c == '5':
+		return string(c), "-"
+	default:
+		if i >= 1 {
+			return string(c), "tegen-1 " + move(opp.moves[i-1])
 		}
-		newPrefix := prefix + string(digit)
-		generateRemaining(newPrefix, remainingLength-1, hasUsedFive || digit == '5', engines)
 	}
+	return string(c), "-"
 }
 
-// **simulateDepthGameToMoves** genereert de zetten van een diepte-gebaseerde engine, reactief op de tegenstander
-func simulateDepthGameToMoves(engine string, opponent string) (moves [13]byte) {
-	if len(engine) != 12 || len(opponent) != 13 {
-		return
+// **explainTarget** geeft het doelelement van een engine voor beurt i, voordat de voorraad meetelt
+// (0 als de zet niet uit een doel volgt, zoals bij vaste en optimale engines of de laatste beurt)
+func explainTarget(engine string, i int, own, opp *Player) byte {
+	if len(engine) == 13 || i == 12 || isOptimalCode(engine) {
+		return 0
+	}
+	base, _ := splitEndgame(engine)
+	base, _ = splitPolicy(base)
+	base, flags := splitInventoryFlags(base)
+	target := engineTarget(base, i, own, opp)
+	if flags != "" {
+		target = inventoryTarget(target, flags, &own.available, &opp.available)
 	}
+	return target
+}
 
-	p := Player{
-		available: [5]int{3, 3, 3, 3, 1},
+// **explainGame** speelt een volledig spel met playGameTraced en schrijft per beurt de gebruikte code, de
+// referentiezet, het doelelement, of er uitgeweken werd, beide zetten, de rondewinnaar, de stand en de voorraden
+func explainGame(engine1, engine2 string, w io.Writer) (p1, p2 Player, ok bool) {
+	engines := [2]string{engine1, engine2}
+	inventory := func(p *Player) string {
+		return fmt.Sprintf("W%d V%d A%d L%d D%d", p.available[0], p.available[1], p.available[2], p.available[3], p.available[4])
 	}
 
-	for i := 0; i < 12; i++ {
-		depth := int(engine[i] - '0')
-		var target byte
-		if depth >= 6 {
-			base := depth - 5
-			if i < 2 {
-				target = getElementFromCode(base)
-			} else {
-				target = getElementByDepth(opponent[i-2], base)
-			}
-		} else if i == 0 {
-			target = getElementFromCode(depth)
-		} else {
-			target = getElementByDepth(opponent[i-1], depth)
-		}
-		move := chooseAvailableElement(target, &p.available)
-		if move != 0 {
-			p.available[moveToIndexArray[move]]--
-			moves[p.moveCount] = move
-			p.moveCount++
-		} else {
-			move = getLastElement(&p.available)
-			if move != 0 {
-				p.available[moveToIndexArray[move]]--
-				moves[p.moveCount] = move
-				p.moveCount++
+	fmt.Fprintf(w, "P1: %s\nP2: %s\n", engine1, engine2)
+	fmt.Fprintf(w, "%5s | %-8s %-11s %4s %5s | %-8s %-11s %4s %5s | %-6s %5s | %-14s | %-14s\n", "beurt", "code", "referentie", "doel", "zet", "code", "referentie", "doel", "zet", "winst", "stand", "voorraad P1", "voorraad P2")
+	p1, p2, ok = playGameTraced(engine1, engine2, false, func(t turnTrace) {
+		side := func(k int) string {
+			own, opp := &t.before[k], &t.before[1-k]
+			token, ref := describeStep(engines[k], t.turn, own, opp)
+			target := explainTarget(engines[k], t.turn, own, opp)
+			if t.planned[k] {
+				token, ref, target = "eindspel", "-", 0
+			}
+			move := string(t.moves[k])
+			if target != 0 && target != t.moves[k] {
+				move += "*" // uitgeweken: het doelelement was niet beschikbaar
 			}
+			if target == 0 {
+				target = t.moves[k]
+			}
+			return fmt.Sprintf("%-8s %-11s %4c %5s", token, ref, target, move)
+		}
+		winner := "gelijk"
+		switch determineWinner(t.moves[0], t.moves[1]) {
+		case 1:
+			winner = "P1"
+		case 2:
+			winner = "P2"
 		}
+		a, b := &t.after[0], &t.after[1]
+		fmt.Fprintf(w, "%5d | %s | %s | %-6s %5s | %-14s | %-14s\n", t.turn+1, side(0), side(1), winner, fmt.Sprintf("%d-%d", a.score, b.score), inventory(a), inventory(b))
+	})
+	if !ok {
+		fmt.Fprintf(w, "Beurt %d: geen geldige zet mogelijk, spel afgebroken.\n", p1.moveCount+1)
+		return p1, p2, false
 	}
+	fmt.Fprintf(w, "Uitslag: %d-%d, matchpunten P1: %d (* = uitgeweken naar een ander element)\n", p1.score, p2.score, matchPoints(p1.score, p2.score))
+	return p1, p2, true
+}
 
-	move := getLastElement(&p.available)
-	if move != 0 {
-		p.available[moveToIndexArray[move]]--
-		moves[p.moveCount] = move
-	} else {
-		moves[p.moveCount] = 'W'
-	}
+// Spelverslagen: een tekstformaat om losse spellen op te slaan en uit te wisselen. Eerst kopregels
+// [Sleutel "waarde"] (Speler1, Speler2, Engine1, Engine2, Regels, Datum, Uitslag), dan een lege regel, dan
+// per beurt "n. zetP1 zetP2" en tot slot de uitslag "p1-p2". Meerdere verslagen volgen elkaar op.
+const rulesVersion = "bote-1"
 
-	return moves
+// **gameRecord** is één spelverslag; de kopregels blijven in volgorde zodat lezen en schrijven elkaar opheffen
+type gameRecord struct {
+	headers [][2]string
+	moves   [][2]byte
+	score   [2]int
 }
 
-// **evaluateBatch** evalueert een batch van engines en berekent de totale score met bonus/malus
-func evaluateBatch(engines []string, inputEngines []string, top10000Chan chan<- engineResult, progressComparisons *int64) {
-    h := &minHeap{}
-    heap.Init(h)
-    maxSize := 10000
-
-    for _, engine := range engines {
-        totalScore := 0
-        for _, inputEngine := range inputEngines {
-            var p1Score, p2Score int
-            if len(inputEngine) == 13 {
-                if len(engine) == 12 {
-                    p1Moves := simulateDepthGameToMoves(engine, inputEngine)
-                    p1Score, p2Score = simulateFixedGame(string(p1Moves[:]), inputEngine)
-                } else {
-                    p1Score, p2Score = simulateFixedGame(engine, inputEngine)
-                }
-            } else {
-                p1Score, p2Score = simulateDepthGame(engine, inputEngine)
-            }
-            if p1Score == -1 || p2Score == -1 {
-                continue
-            }
+// **header** geeft de waarde van een kopregel ("" als die ontbreekt)
+func (r *gameRecord) header(key string) string {
+	for _, h := range r.headers {
+		if h[0] == key {
+			return h[1]
+		}
+	}
+	return ""
+}
 
-            // Nieuwe scoreberekening
-            diff := p1Score - p2Score
-            if p1Score > p2Score {
-                totalScore += diff + 10 // Winst: +10 bonus
-            } else if p1Score < p2Score {
-                totalScore += diff - 10 // Verlies: -10 malus
-            } else {
-                totalScore += p1Score   // Gelijkspel: +p1Score
-            }
+// **setHeader** zet een kopregel, of voegt hem achteraan toe
+func (r *gameRecord) setHeader(key, value string) {
+	for k := range r.headers {
+		if r.headers[k][0] == key {
+			r.headers[k][1] = value
+			return
+		}
+	}
+	r.headers = append(r.headers, [2]string{key, value})
+}
 
-            // Update voortgang na elke match
-            atomic.AddInt64(progressComparisons, 1)
-            p := atomic.LoadInt64(progressComparisons)
-            if p % updateInterval == 0 {
-                printMu.Lock()
-                elapsed := time.Since(startTime).Seconds()
-                if elapsed > 0 {
-                    speed := float64(p) / elapsed / 1000 // k matches/s
-                    fmt.Printf("Voortgang: %d / %d matches (%.2f%%), Snelheid: %.1f k matches/s\n",
-                        p, totalComparisons, float64(p)/float64(totalComparisons)*100, speed)
-                } else {
-                    fmt.Printf("Voortgang: %d / %d matches (%.2f%%)\n",
-                        p, totalComparisons, float64(p)/float64(totalComparisons)*100)
-                }
-                printMu.Unlock()
-            }
-        }
+// **removeHeader** verwijdert een kopregel
+func (r *gameRecord) removeHeader(key string) {
+	for k := range r.headers {
+		if r.headers[k][0] == key {
+			r.headers = append(r.headers[:k], r.headers[k+1:]...)
+			return
+		}
+	}
+}
 
-        result := engineResult{engine: engine, score: totalScore}
-        if h.Len() < maxSize {
-            heap.Push(h, result)
-        } else if totalScore > (*h)[0].score {
-            heap.Pop(h)
-            heap.Push(h, result)
-        }
-    }
+// **newGameRecord** maakt een verslag van een gespeeld spel tussen twee engines
+func newGameRecord(engine1, engine2 string, p1, p2 Player) gameRecord {
+	r := gameRecord{score: [2]int{p1.score, p2.score}}
+	r.setHeader("Speler1", engine1)
+	r.setHeader("Speler2", engine2)
+	r.setHeader("Engine1", engine1)
+	r.setHeader("Engine2", engine2)
+	r.setHeader("Regels", rulesVersion)
+	r.setHeader("Datum", time.Now().Format("2006-01-02"))
+	r.setHeader("Uitslag", fmt.Sprintf("%d-%d", p1.score, p2.score))
+	for i := 0; i < p1.moveCount && i < p2.moveCount; i++ {
+		r.moves = append(r.moves, [2]byte{p1.moves[i], p2.moves[i]})
+	}
+	return r
+}
 
-    for h.Len() > 0 {
-        top10000Chan <- heap.Pop(h).(engineResult)
-    }
+// **recordScore** telt de rondes van een zettenlijst op volgens de gewone regels
+func recordScore(moves [][2]byte) [2]int {
+	var score [2]int
+	for _, pair := range moves {
+		switch determineWinner(pair[0], pair[1]) {
+		case 1:
+			score[0]++
+		case 2:
+			score[1]++
+		}
+	}
+	return score
 }
 
-// **parseEngineCode** haalt de engine code uit een invoer met prefix en kapt af na 12 cijfers voor depth engines
-func parseEngineCode(input string) string {
-	parts := strings.Split(input, ":")
-	engine := strings.TrimSpace(input)
-	if len(parts) > 2 {
-		engine = strings.TrimSpace(parts[2])
+// **writeGameRecord** schrijft een verslag in het tekstformaat, gevolgd door een lege regel
+func writeGameRecord(w io.Writer, r gameRecord) error {
+	var b strings.Builder
+	for _, h := range r.headers {
+		fmt.Fprintf(&b, "[%s %s]\n", h[0], strconv.Quote(h[1]))
 	}
-	if len(engine) > 12 && strings.ContainsAny(engine, "123456789") && !strings.ContainsAny(engine, "WVALD") {
-		return engine[:12]
+	b.WriteString("\n")
+	for i, pair := range r.moves {
+		fmt.Fprintf(&b, "%d. %c %c\n", i+1, pair[0], pair[1])
 	}
-	return engine
+	fmt.Fprintf(&b, "%d-%d\n\n", r.score[0], r.score[1])
+	_, err := io.WriteString(w, b.String())
+	return err
 }
 
-func main() {
-	for {
-		var inputEngines []string
-		fmt.Println("Voer engine codes in (één per regel, '.' om te stoppen):")
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			input := strings.TrimSpace(scanner.Text())
-			if input == "." || input == "" {
-				break
+// **readGameRecords** leest alle verslagen uit r. De uitslag wordt nagerekend uit de zetten; een afwijkende
+// uitslag of een ongeldige zet is een fout met het regelnummer.
+func readGameRecords(r io.Reader) ([]gameRecord, error) {
+	var records []gameRecord
+	var current *gameRecord
+	var available [2][5]int
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, ";") {
+			continue // lege regels en commentaar
+		}
+		if current == nil {
+			records = append(records, gameRecord{})
+			current = &records[len(records)-1]
+			available = [2][5]int{{3, 3, 3, 3, 1}, {3, 3, 3, 3, 1}}
+		}
+		switch {
+		case strings.HasPrefix(text, "["):
+			if len(current.moves) > 0 {
+				return records, fmt.Errorf("regel %d: kopregel na de zetten", line)
+			}
+			key, value, found := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(text, "["), "]"), " ")
+			if !found || !strings.HasSuffix(text, "]") {
+				return records, fmt.Errorf("regel %d: ongeldige kopregel %q", line, text)
+			}
+			unquoted, err := strconv.Unquote(strings.TrimSpace(value))
+			if err != nil {
+				return records, fmt.Errorf("regel %d: ongeldige waarde %s", line, value)
+			}
+			current.setHeader(key, unquoted)
+		case strings.Count(text, "-") == 1 && strings.Trim(text, "0123456789-") == "":
+			if _, err := fmt.Sscanf(text, "%d-%d", &current.score[0], &current.score[1]); err != nil {
+				return records, fmt.Errorf("regel %d: ongeldige uitslag %q", line, text)
 			}
-			engine := parseEngineCode(input)
-			validDepth := len(engine) == 12 && !strings.ContainsAny(engine, "0") && strings.ContainsAny(engine, "123456789")
-			validFixed := len(engine) == 13 && strings.ContainsAny(engine, "WVALD") && !strings.ContainsAny(engine, "1234567890")
-			if validDepth || validFixed {
-				inputEngines = append(inputEngines, engine)
-			} else {
-				fmt.Printf("Ongeldige engine code '%s'. Moet 12 chiffres (1-9) of 13 tekens (W, V, A, L, D) zijn.\n", engine)
+			if computed := recordScore(current.moves); computed != current.score {
+				return records, fmt.Errorf("regel %d: uitslag %s klopt niet met de zetten (%d-%d)", line, text, computed[0], computed[1])
 			}
+			current = nil
+		default:
+			fields := strings.Fields(text)
+			if len(fields) == 3 && strings.HasSuffix(fields[0], ".") {
+				fields = fields[1:]
+			}
+			if len(fields) != 2 || len(fields[0]) != 1 || len(fields[1]) != 1 || !isMoveLetter(fields[0][0]) || !isMoveLetter(fields[1][0]) {
+				return records, fmt.Errorf("regel %d: ongeldige zet %q", line, text)
+			}
+			if len(current.moves) == 13 {
+				return records, fmt.Errorf("regel %d: meer dan 13 zetten", line)
+			}
+			for k := 0; k < 2; k++ {
+				m := fields[k][0]
+				if available[k][moveToIndexArray[m]] == 0 {
+					return records, fmt.Errorf("regel %d: speler %d speelt meer %c dan de voorraad toelaat", line, k+1, m)
+				}
+				available[k][moveToIndexArray[m]]--
+			}
+			current.moves = append(current.moves, [2]byte{fields[0][0], fields[1][0]})
 		}
-		if err := scanner.Err(); err != nil {
-			fmt.Printf("Fout bij het lezen van invoer: %v\n", err)
-			continue
+	}
+	if current != nil {
+		return records, fmt.Errorf("verslag zonder uitslag aan het einde van de invoer")
+	}
+	return records, scanner.Err()
+}
+
+// **humanLine** is één gespeelde lijn van een mens: 13 zetten die als vaste tegenstander gelden
+type humanLine struct {
+	name  string
+	moves string
+}
+
+// **humanLinesFromRecords** haalt de menselijke lijnen uit spelverslagen. Met side "auto" telt elke kant zonder
+// Engine-kopregel als mens, met "1", "2" of "both" worden die kanten genomen.
+func humanLinesFromRecords(records []gameRecord, side string) []humanLine {
+	var lines []humanLine
+	for _, r := range records {
+		if len(r.moves) != 13 {
+			continue
+		}
+		for k := 0; k < 2; k++ {
+			key := strconv.Itoa(k + 1)
+			switch side {
+			case "auto":
+				if r.header("Engine"+key) != "" {
+					continue
+				}
+			case "both":
+			default:
+				if side != key {
+					continue
+				}
+			}
+			moves := make([]byte, 13)
+			for i, pair := range r.moves {
+				moves[i] = pair[k]
+			}
+			name := r.header("Speler" + key)
+			if name == "" {
+				name = "speler" + key
+			}
+			lines = append(lines, humanLine{name: name, moves: string(moves)})
+		}
+	}
+	return lines
+}
+
+// **humanLinesFromCSV** leest een CSV-log met per rij een spelernaam en de 13 zetten, als één kolom
+// ("WVALD...") of als 13 losse kolommen; rijen zonder geldige zetten (zoals een kopregel) worden overgeslagen
+func humanLinesFromCSV(r io.Reader) ([]humanLine, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var lines []humanLine
+	skipped := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return lines, skipped, nil
+		}
+		if err != nil {
+			return lines, skipped, err
+		}
+		if len(row) < 2 {
+			skipped++
+			continue
+		}
+		moves := strings.ToUpper(strings.Join(row[1:], ""))
+		moves = strings.NewReplacer(" ", "", "-", "").Replace(moves)
+		if len(moves) != 13 || strings.Trim(moves, "WVALD") != "" {
+			skipped++
+			continue
+		}
+		lines = append(lines, humanLine{name: strings.TrimSpace(row[0]), moves: moves})
+	}
+}
+
+// **observedGame** is een waargenomen spel: onze zetten en die van de tegenstander (0 = niet gezien)
+type observedGame struct {
+	ours   [13]byte
+	theirs [13]byte
+	length int // aantal gespeelde beurten
+}
+
+// **parseObservedGame** leest "ONZE/HUNNE": onze zetten en die van de tegenstander, '?' of '.' voor een
+// onbekende zet van de tegenstander; een korter spel telt alleen de gespeelde beurten
+func parseObservedGame(text string) (observedGame, error) {
+	var g observedGame
+	ours, theirs, found := strings.Cut(strings.ToUpper(strings.TrimSpace(text)), "/")
+	if !found || len(ours) == 0 || len(ours) > 13 || len(theirs) > len(ours) {
+		return g, fmt.Errorf("ongeldig spel %q, verwacht ONZE/HUNNE met hoogstens 13 zetten", text)
+	}
+	for i := 0; i < len(ours); i++ {
+		if !isMoveLetter(ours[i]) {
+			return g, fmt.Errorf("ongeldige eigen zet %q in %q", ours[i], text)
+		}
+		g.ours[i] = ours[i]
+	}
+	for i := 0; i < len(theirs); i++ {
+		switch {
+		case isMoveLetter(theirs[i]):
+			g.theirs[i] = theirs[i]
+		case theirs[i] != '?' && theirs[i] != '.':
+			return g, fmt.Errorf("ongeldige zet van de tegenstander %q in %q", theirs[i], text)
+		}
+	}
+	g.length = len(ours)
+	return g, nil
+}
+
+// **inferredCode** is een (groep) diepte-code(s) die bij de waarnemingen past; '?' staat voor een vrije positie
+type inferredCode struct {
+	pattern    string
+	count      int64 // aantal codes achter het patroon
+	mismatches int
+}
+
+// **codeInference** zoekt alle 12-cijferige diepte-codes van de tegenstander die, gespeeld zoals in
+// simulateDepthGameToMoves tegen onze zetten, hoogstens maxErrors waargenomen zetten anders spelen
+type codeInference struct {
+	games     []observedGame
+	digits    string
+	maxErrors int
+	keep      int   // maximaal bewaarde patronen per aantal fouten
+	budget    int64 // maximaal aantal bezochte knopen
+	nodes     int64
+	counts    []int64 // aantal codes per aantal fouten
+	found     [][]inferredCode
+}
+
+// **lastObserved** geeft de hoogste beurt met een waargenomen zet van de tegenstander (-1 = geen)
+func (ci *codeInference) lastObserved() int {
+	last := -1
+	for _, g := range ci.games {
+		for i := 0; i < g.length; i++ {
+			if g.theirs[i] != 0 && i > last {
+				last = i
+			}
+		}
+	}
+	return last
+}
+
+// **completions** telt de vrije aanvullingen van positie i tot 12, met hoogstens één 5 in de hele code
+func (ci *codeInference) completions(i int, fiveUsed bool) int64 {
+	var none, one int64 = 1, 0 // aanvullingen zonder en met één 5
+	if fiveUsed {
+		one, none = 1, 0
+	}
+	for pos := i; pos < 12; pos++ {
+		other := int64(0)
+		for k := 0; k < len(ci.digits); k++ {
+			if ci.digits[k] != '5' && (pos > 0 || ci.digits[k] < '5') {
+				other++
+			}
+		}
+		none, one = none*other, one*other+none
+	}
+	return none + one
+}
+
+// **search** probeert elk cijfer op positie i en volgt per spel de staat van de tegenstander
+func (ci *codeInference) search(code []byte, i int, states []Player, mismatches int, fiveUsed bool, freeFrom int) bool {
+	ci.nodes++
+	if ci.nodes > ci.budget {
+		return false
+	}
+	if i == 12 || i > freeFrom {
+		count := int64(1)
+		pattern := string(code[:i])
+		if i == 12 {
+			for g := range ci.games {
+				if game := &ci.games[g]; game.length == 13 && game.theirs[12] != 0 && getLastElement(&states[g].available) != game.theirs[12] {
+					mismatches++
+				}
+			}
+			if mismatches > ci.maxErrors {
+				return true
+			}
+		} else {
+			count = ci.completions(i, fiveUsed)
+			pattern += strings.Repeat("?", 12-i)
+		}
+		ci.counts[mismatches] += count
+		if len(ci.found[mismatches]) < ci.keep {
+			ci.found[mismatches] = append(ci.found[mismatches], inferredCode{pattern: pattern, count: count, mismatches: mismatches})
+		}
+		return true
+	}
+	next := make([]Player, len(states))
+	for k := 0; k < len(ci.digits); k++ {
+		c := ci.digits[k]
+		if (c == '5' && fiveUsed) || (i == 0 && c > '5') {
+			continue
+		}
+		errors := mismatches
+		copy(next, states)
+		for g := range ci.games {
+			game := &ci.games[g]
+			if i >= game.length {
+				continue
+			}
+			p := &next[g]
+			move := chooseAvailableElement(stepTarget(c, i, &p.moves, &game.ours), &p.available)
+			if move == 0 {
+				move = getLastElement(&p.available)
+			}
+			if move != 0 {
+				p.available[moveToIndexArray[move]]--
+			}
+			p.moves[p.moveCount] = move
+			p.moveCount++
+			if game.theirs[i] != 0 && move != game.theirs[i] {
+				errors++
+			}
+		}
+		if errors > ci.maxErrors {
+			continue
+		}
+		code[i] = c
+		if !ci.search(code, i+1, next, errors, fiveUsed || c == '5', freeFrom) {
+			return false
+		}
+	}
+	return true
+}
+
+// **run** voert de zoektocht uit tot en met positie freeFrom (daarna is de code vrij); ok is false als het
+// knopenbudget op is
+func (ci *codeInference) run(freeFrom int) (ok bool) {
+	ci.nodes = 0
+	ci.counts = make([]int64, ci.maxErrors+1)
+	ci.found = make([][]inferredCode, ci.maxErrors+1)
+	states := make([]Player, len(ci.games))
+	for g := range states {
+		states[g].available = [5]int{3, 3, 3, 3, 1}
+	}
+	if freeFrom > 11 {
+		freeFrom = 11 // de laatste beurt is gedwongen
+	}
+	return ci.search(make([]byte, 12), 0, states, 0, false, freeFrom)
+}
+
+// **depthMoveAt** speelt een diepte-code (posities na t mogen '?' zijn) zoals simulateDepthGameToMoves tegen
+// onze zetten en geeft de zet van beurt t
+func depthMoveAt(code string, ours *[13]byte, t int) byte {
+	p := Player{available: [5]int{3, 3, 3, 3, 1}}
+	for i := 0; i <= t; i++ {
+		move := byte(0)
+		if i < 12 {
+			move = chooseAvailableElement(stepTarget(code[i], i, &p.moves, ours), &p.available)
+		}
+		if move == 0 {
+			move = getLastElement(&p.available)
+		}
+		if move == 0 {
+			return 0
+		}
+		if i == t {
+			return move
+		}
+		p.available[moveToIndexArray[move]]--
+		p.moves[i] = move
+	}
+	return 0
+}
+
+// **beliefEntry** is één mogelijke tegenstander met zijn gewicht en voorspelde volgende zet
+type beliefEntry struct {
+	label  string
+	weight float64
+	next   byte
+}
+
+// **opponentBelief** bouwt de verdeling over de mogelijke engines van de tegenstander na t beurten. Pool-engines
+// wegen zoals in het bestand en vallen af (of wegen noise^fouten) als ze een gespeelde zet niet verklaren;
+// de diepte-codes samen wegen depthWeight, verdeeld over alle codes die de zetten exact verklaren.
+func opponentBelief(ours, theirs *[13]byte, t int, pool []string, weights []float64, noise float64, depthWeight float64, digits string, budget int64) ([]beliefEntry, bool) {
+	var belief []beliefEntry
+	poolTotal := 0.0
+	for _, w := range weights {
+		poolTotal += w
+	}
+	for k, engine := range pool {
+		engine, _ = splitEndgame(engine) // zonder onze code valt het eindspel niet op te lossen
+		var us, them Player
+		us.available = [5]int{3, 3, 3, 3, 1}
+		them.available = [5]int{3, 3, 3, 3, 1}
+		weight := weights[k] / poolTotal
+		for i := 0; i < t && weight > 0; i++ {
+			if engineMove(engine, i, &them, &us) != theirs[i] {
+				weight *= noise
+			}
+			playMove(&them, theirs[i])
+			playMove(&us, ours[i])
+			switch determineWinner(ours[i], theirs[i]) {
+			case 1:
+				us.score++
+			case 2:
+				them.score++
+			}
+		}
+		if weight > 0 {
+			belief = append(belief, beliefEntry{label: pool[k], weight: weight, next: engineMove(engine, t, &them, &us)})
+		}
+	}
+	if depthWeight <= 0 {
+		return belief, true
+	}
+	game := observedGame{ours: *ours, length: t + 1}
+	copy(game.theirs[:t], theirs[:t])
+	ci := &codeInference{games: []observedGame{game}, digits: digits, keep: math.MaxInt, budget: budget}
+	if !ci.run(t) {
+		return belief, false
+	}
+	all := float64(ci.completions(0, false))
+	for _, code := range ci.found[0] {
+		belief = append(belief, beliefEntry{label: code.pattern, weight: depthWeight * float64(code.count) / all, next: depthMoveAt(code.pattern, ours, t)})
+	}
+	return belief, true
+}
+
+// Eindspel-codes: een code met suffix "#k" speelt de basiscode voor beurt 1..13-k en lost de laatste k beurten
+// exact op tegen de (bekende, deterministische) tegenstander. Lost de tegenstander zelf ook op, dan neemt
+// de oplosser aan dat die zijn basiscode speelt.
+
+// **splitEndgame** splitst een code in de basiscode en het aantal exact opgeloste slotbeurten
+func splitEndgame(engine string) (string, int) {
+	if n := len(engine); n >= 2 && engine[n-2] == '#' && engine[n-1] >= '1' && engine[n-1] <= '9' {
+		return engine[:n-2], int(engine[n-1] - '0')
+	}
+	return engine, 0
+}
+
+// **solveEndgame** zoekt exhaustief onze beste zetten vanaf beurt from, gegeven beide voorraden en de
+// tegenstander; de waarde is matchPoints van de einduitslag
+func solveEndgame(own, opp Player, opponent string, from int) ([]byte, int) {
+	if from == 13 {
+		return nil, matchPoints(own.score, opp.score)
+	}
+	oppMove := engineMove(opponent, from, &opp, &own) // gelijktijdig: hangt niet af van onze zet nu
+	var bestMoves []byte
+	bestPoints := 0
+	for idx, move := range depthToElement {
+		if own.available[idx] == 0 {
+			continue
+		}
+		nextOwn, nextOpp := own, opp
+		playMove(&nextOwn, move)
+		playMove(&nextOpp, oppMove)
+		switch determineWinner(move, oppMove) {
+		case 1:
+			nextOwn.score++
+		case 2:
+			nextOpp.score++
+		}
+		rest, points := solveEndgame(nextOwn, nextOpp, opponent, from+1)
+		if bestMoves == nil || points > bestPoints {
+			bestMoves = append([]byte{move}, rest...)
+			bestPoints = points
+		}
+	}
+	return bestMoves, bestPoints
+}
+
+// **planMove** haalt zet j uit een opgelost eindspel, met getLastElement als het plan ontbreekt
+func planMove(plan []byte, j int, available *[5]int) byte {
+	if j < len(plan) {
+		return plan[j]
+	}
+	return getLastElement(available)
+}
+
+// Stochastische codes: 'p' gevolgd door 12 posities gescheiden door '.', elk een dieptecijfer of een verdeling
+// zoals "2=60|3=40" (percentages, samen 100), eventueel gevolgd door modifiers ("!wv", "~p", "#k"). Elke
+// match trekt per positie één cijfer; een trekking is dus een gewone code die de bestaande simulaties spelen.
+
+// **stochasticChoice** is één mogelijk cijfer op een positie met zijn kans
+type stochasticChoice struct {
+	digit byte
+	prob  float64
+}
+
+// **stochasticResult** houdt de verwachte score en variantie van een stochastische engine bij
+type stochasticResult struct {
+	engine   string
+	mean     float64
+	variance float64
+	exact    bool
+}
+
+// **parseStochasticCode** ontleedt een stochastische code in de verdelingen per positie en de modifiers
+func parseStochasticCode(engine string) ([12][]stochasticChoice, string, bool) {
+	var positions [12][]stochasticChoice
+	if !strings.HasPrefix(engine, "p") {
+		return positions, "", false
+	}
+	body, suffix := engine[1:], ""
+	if idx := strings.IndexAny(body, "!~#"); idx >= 0 {
+		body, suffix = body[:idx], body[idx:]
+	}
+	parts := strings.Split(body, ".")
+	if len(parts) != 12 {
+		return positions, "", false
+	}
+	for i, part := range parts {
+		if len(part) == 1 && isDepthDigit(part[0]) {
+			positions[i] = []stochasticChoice{{digit: part[0], prob: 1}}
+			continue
+		}
+		total := 0
+		for _, option := range strings.Split(part, "|") {
+			if len(option) < 3 || option[1] != '=' || !isDepthDigit(option[0]) {
+				return positions, "", false
+			}
+			percent, err := strconv.Atoi(option[2:]) // weigert ook rommel na het getal, zoals "60x"
+			if err != nil || percent <= 0 {
+				return positions, "", false
+			}
+			total += percent
+			positions[i] = append(positions[i], stochasticChoice{digit: option[0], prob: float64(percent) / 100})
+		}
+		if total != 100 {
+			return positions, "", false
+		}
+	}
+	for _, choice := range positions[0] {
+		if choice.digit > '5' {
+			return positions, "", false // eerste positie mag alleen 1-5 zijn
+		}
+	}
+	return positions, suffix, isValidEngineCode(strings.Repeat("1", 12) + suffix)
+}
+
+// **stochasticRealizations** geeft alle trekkingen met hun kans, of nil als het er meer dan limit zijn
+func stochasticRealizations(positions [12][]stochasticChoice, suffix string, limit int) ([]string, []float64) {
+	count := 1
+	for _, choices := range positions {
+		count *= len(choices)
+		if count > limit {
+			return nil, nil
+		}
+	}
+	codes, probs := []string{""}, []float64{1}
+	for _, choices := range positions {
+		var nextCodes []string
+		var nextProbs []float64
+		for j, code := range codes {
+			for _, choice := range choices {
+				nextCodes = append(nextCodes, code+string(choice.digit))
+				nextProbs = append(nextProbs, probs[j]*choice.prob)
+			}
+		}
+		codes, probs = nextCodes, nextProbs
+	}
+	for j := range codes {
+		codes[j] += suffix
+	}
+	return codes, probs
+}
+
+// **sampleRealization** trekt één gewone code uit een stochastische code
+func sampleRealization(positions [12][]stochasticChoice, suffix string, rng *rand.Rand) string {
+	code := make([]byte, 12)
+	for i, choices := range positions {
+		r := rng.Float64()
+		code[i] = choices[len(choices)-1].digit
+		for _, choice := range choices {
+			if r < choice.prob {
+				code[i] = choice.digit
+				break
+			}
+			r -= choice.prob
+		}
+	}
+	return string(code) + suffix
+}
+
+// **expectedScore** berekent de verwachte totaalscore en de variantie tegen een pool van deterministische
+// tegenstanders: exact over de kansboom als die hoogstens limit bladeren heeft, anders met Monte Carlo
+func expectedScore(engine string, inputEngines []string, limit, samples int, rng *rand.Rand) stochasticResult {
+	result := stochasticResult{engine: engine}
+	positions, suffix, ok := parseStochasticCode(engine)
+	if !ok {
+		return result
+	}
+	codes, probs := stochasticRealizations(positions, suffix, limit)
+	result.exact = codes != nil
+	for _, inputEngine := range inputEngines {
+		var mean, square float64
+		if result.exact {
+			for j, code := range codes {
+				points := float64(realizationPoints(code, inputEngine))
+				mean += probs[j] * points
+				square += probs[j] * points * points
+			}
+		} else {
+			for j := 0; j < samples; j++ {
+				points := float64(realizationPoints(sampleRealization(positions, suffix, rng), inputEngine))
+				mean += points / float64(samples)
+				square += points * points / float64(samples)
+			}
+		}
+		// De matches zijn onafhankelijke trekkingen, dus de varianties tellen op
+		result.mean += mean
+		result.variance += square - mean*mean
+	}
+	return result
+}
+
+// **realizationPoints** speelt één trekking tegen een tegenstander; ongeldige matches tellen als 0 punten
+func realizationPoints(code, inputEngine string) int {
+	p1Score, p2Score := simulateMatch(code, inputEngine)
+	if p1Score == -1 || p2Score == -1 {
+		return 0
+	}
+	return matchPoints(p1Score, p2Score)
+}
+
+// Conditionele codes: twee of drie diepte-/hybride codes gescheiden door '/', per beurt gekozen op het
+// puntverschil. "A/B": A als we niet voorstaan, B als we voorstaan. "A/B/C": A bij achterstand, B bij
+// gelijke stand, C bij voorsprong.
+
+// **isConditionalCode** geeft aan of een code een conditionele code is
+func isConditionalCode(engine string) bool {
+	return (len(engine) == 25 || len(engine) == 38) && engine[12] == '/'
+}
+
+// **isValidConditionalCode** controleert een conditionele code
+func isValidConditionalCode(engine string) bool {
+	if !isConditionalCode(engine) {
+		return false
+	}
+	for _, part := range strings.Split(engine, "/") {
+		if !isValidDepthCode(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// **conditionalBranch** kiest de deelcode van een conditionele code bij een puntverschil (eigen - tegenstander)
+func conditionalBranch(engine string, diff int) string {
+	branch := 0
+	if len(engine) == 25 {
+		if diff > 0 {
+			branch = 1
+		}
+	} else if diff == 0 {
+		branch = 1
+	} else if diff > 0 {
+		branch = 2
+	}
+	return engine[branch*13 : branch*13+12]
+}
+
+// FSM-codes: 'f' gevolgd door toestanden gescheiden door '-'. Elke toestand is een diepte (1-5) die vanaf
+// de vorige zet van de tegenstander roteert, gevolgd door 15 volgende toestanden (0-9): één per combinatie
+// van het laatste element van de tegenstander (W, V, A, L, D) en onze uitslag (winst, gelijk, verlies).
+// Het spel begint in toestand 0; de eerste zet gebruikt de diepte als vast element.
+const fsmSymbols = 15
+const fsmStateLength = 1 + fsmSymbols
+
+// **isFSMCode** geeft aan of een code een FSM-code is
+func isFSMCode(engine string) bool {
+	return len(engine) >= 1+fsmStateLength && engine[0] == 'f' && len(engine)%(fsmStateLength+1) == 0
+}
+
+// **isValidFSMCode** controleert een FSM-code
+func isValidFSMCode(engine string) bool {
+	if !isFSMCode(engine) {
+		return false
+	}
+	states := strings.Split(engine[1:], "-")
+	if len(states) > 10 {
+		return false
+	}
+	for _, state := range states {
+		if len(state) != fsmStateLength || state[0] < '1' || state[0] > '5' {
+			return false
+		}
+		for _, next := range state[1:] {
+			if next < '0' || int(next-'0') >= len(states) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// **fsmSymbol** codeert het laatste element van de tegenstander en onze uitslag als invoersymbool
+func fsmSymbol(ownMove, oppMove byte) int {
+	result := 1 // gelijk
+	switch determineWinner(ownMove, oppMove) {
+	case 1:
+		result = 0
+	case 2:
+		result = 2
+	}
+	return moveToIndexArray[oppMove]*3 + result
+}
+
+// **fsmTarget** speelt de toestandsmachine af over de geschiedenis en bepaalt het doelelement voor beurt i
+func fsmTarget(engine string, i int, own, opp *[13]byte) byte {
+	state := 0
+	for j := 0; j < i; j++ {
+		state = int(engine[1+state*(fsmStateLength+1)+1+fsmSymbol(own[j], opp[j])] - '0')
+	}
+	depth := int(engine[1+state*(fsmStateLength+1)] - '0')
+	if i == 0 {
+		return getElementFromCode(depth)
+	}
+	return getElementByDepth(opp[i-1], depth)
+}
+
+// **encodeFSM** zet diepte per toestand en overgangstabel om naar een FSM-code
+func encodeFSM(emits []byte, transitions [][fsmSymbols]byte) string {
+	var sb strings.Builder
+	sb.WriteByte('f')
+	for state := range emits {
+		if state > 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte('0' + emits[state])
+		for _, next := range transitions[state] {
+			sb.WriteByte('0' + next)
+		}
+	}
+	return sb.String()
+}
+
+// **countFSMs** telt alle FSM's met n toestanden (5^n dieptes × n^(15n) overgangen), afgekapt op limit+1
+func countFSMs(states int, limit int64) int64 {
+	count := int64(1)
+	for i := 0; i < states; i++ {
+		count *= 5
+		for j := 0; j < fsmSymbols; j++ {
+			count *= int64(states)
+			if count > limit {
+				return limit + 1
+			}
+		}
+	}
+	return count
+}
+
+// **generateFSMs** genereert alle FSM-codes met het gegeven aantal toestanden
+func generateFSMs(states int) []string {
+	var engines []string
+	emits := make([]byte, states)
+	transitions := make([][fsmSymbols]byte, states)
+	var fill func(pos int)
+	fill = func(pos int) {
+		if pos == states*fsmStateLength {
+			engines = append(engines, encodeFSM(emits, transitions))
+			return
+		}
+		state, field := pos/fsmStateLength, pos%fsmStateLength
+		if field == 0 {
+			for depth := byte(1); depth <= 5; depth++ {
+				emits[state] = depth
+				fill(pos + 1)
+			}
+			return
+		}
+		for next := 0; next < states; next++ {
+			transitions[state][field-1] = byte(next)
+			fill(pos + 1)
+		}
+	}
+	fill(0)
+	return engines
+}
+
+// **randomFSM** maakt een willekeurige FSM met het gegeven aantal toestanden
+func randomFSM(states int, rng *rand.Rand) ([]byte, [][fsmSymbols]byte) {
+	emits := make([]byte, states)
+	transitions := make([][fsmSymbols]byte, states)
+	for state := 0; state < states; state++ {
+		emits[state] = byte(1 + rng.Intn(5))
+		for j := range transitions[state] {
+			transitions[state][j] = byte(rng.Intn(states))
+		}
+	}
+	return emits, transitions
+}
+
+// **climbFSM** verbetert een willekeurige FSM met hill climbing: één diepte of overgang tegelijk aanpassen
+func climbFSM(states, iterations int, inputEngines []string, rng *rand.Rand) engineResult {
+	emits, transitions := randomFSM(states, rng)
+	best := engineResult{engine: encodeFSM(emits, transitions)}
+	best.score = scoreEngine(best.engine, inputEngines)
+	for it := 0; it < iterations; it++ {
+		state := rng.Intn(states)
+		field := rng.Intn(fsmStateLength)
+		oldEmit, oldNext := emits[state], byte(0)
+		if field == 0 {
+			emits[state] = byte(1 + rng.Intn(5))
+		} else {
+			oldNext = transitions[state][field-1]
+			transitions[state][field-1] = byte(rng.Intn(states))
+		}
+		candidate := encodeFSM(emits, transitions)
+		if score := scoreEngine(candidate, inputEngines); score >= best.score {
+			best = engineResult{engine: candidate, score: score}
+			continue
+		}
+		if field == 0 {
+			emits[state] = oldEmit
+		} else {
+			transitions[state][field-1] = oldNext
+		}
+	}
+	return best
+}
+
+// **generateConditionalEngines** combineert kandidaten tot alle conditionele codes met twee of drie takken
+func generateConditionalEngines(candidates []string, branches int) []string {
+	var engines []string
+	for _, a := range candidates {
+		for _, b := range candidates {
+			if branches == 2 {
+				if a != b {
+					engines = append(engines, a+"/"+b)
+				}
+				continue
+			}
+			for _, c := range candidates {
+				if a != b || b != c {
+					engines = append(engines, a+"/"+b+"/"+c)
+				}
+			}
+		}
+	}
+	return engines
+}
+
+// **generateEngines** genereert alle engine codes met max 1 '5', met op elke positie een cijfer uit digits,
+// eenmaal per fallback-policy uit policies (leeg = alleen de standaard)
+func generateEngines(startDepth, digits, policies string) []string {
+	var engines []string
+	remainingLength := 12 - len(startDepth)
+	hasFive := strings.Contains(startDepth, "5")
+
+	if remainingLength < 0 {
+		return engines
+	}
+
+	if startDepth != "" && (isMoveLetter(startDepth[0]) || startDepth[0] == '*') {
+		return generateHybridEngines(startDepth, digits, policies)
+	}
+
+	if startDepth != "" {
+		for i, digit := range startDepth {
+			if !isDepthDigit(byte(digit)) {
+				return engines
+			}
+			if i == 0 && digit > '5' {
+				return engines // eerste positie mag alleen 1-5 zijn
+			}
+		}
+		generateRemaining(startDepth, remainingLength, hasFive, digits, policies, &engines)
+	} else {
+		for firstDigit := '1'; firstDigit <= '5'; firstDigit++ { // eerste positie: enkel 1-5
+			prefix := string(firstDigit)
+			hasFiveLocal := firstDigit == '5'
+			generateRemaining(prefix, 11, hasFiveLocal, digits, policies, &engines)
+		}
+	}
+
+	return engines
+}
+
+// **classicDepthDigits** zijn de cijfers die de generators standaard op elke positie proberen; met
+// eigen-relatieve dieptes komt daar ownDepthDigits bij
+const classicDepthDigits = "123456789"
+const ownDepthDigits = "abcd"
+
+// **appendWithPolicies** voegt een code toe, eenmaal per fallback-policy uit policies (leeg = alleen de standaard)
+func appendWithPolicies(engine, policies string, engines *[]string) {
+	if policies == "" {
+		*engines = append(*engines, engine)
+		return
+	}
+	for i := 0; i < len(policies); i++ {
+		if policies[i] == '1' {
+			*engines = append(*engines, engine)
+		} else {
+			*engines = append(*engines, engine+"~"+string(policies[i]))
+		}
+	}
+}
+
+// **generateRemaining** genereert de resterende posities iteratief
+func generateRemaining(prefix string, remainingLength int, hasUsedFive bool, digits, policies string, engines *[]string) {
+	if remainingLength == 0 {
+		if len(prefix) == 12 {
+			appendWithPolicies(prefix, policies, engines)
+		}
+		return
+	}
+
+	for _, digit := range digits {
+		if digit == '5' && hasUsedFive {
+			continue
+		}
+		newPrefix := prefix + string(digit)
+		generateRemaining(newPrefix, remainingLength-1, hasUsedFive || digit == '5', digits, policies, engines)
+	}
+}
+
+// **generateHybridEngines** genereert alle hybride codes: vaste opening (letters, '*' = elk element) × dieptesuffix
+func generateHybridEngines(startDepth, digits, policies string) []string {
+	var engines []string
+	openingLength := 0
+	for openingLength < len(startDepth) && (isMoveLetter(startDepth[openingLength]) || startDepth[openingLength] == '*') {
+		openingLength++
+	}
+	suffix := startDepth[openingLength:]
+	for _, digit := range suffix {
+		if !isDepthDigit(byte(digit)) {
+			return engines
+		}
+	}
+
+	for _, opening := range expandOpenings(startDepth[:openingLength]) {
+		prefix := opening + suffix
+		hasFive := strings.ContainsAny(prefix, "5D") // D uit de opening telt als de enige '5'
+		if strings.Count(prefix, "5")+strings.Count(prefix, "D") > 1 {
+			continue
+		}
+		generateRemaining(prefix, 12-len(prefix), hasFive, digits, policies, &engines)
+	}
+	return engines
+}
+
+// **expandOpenings** vervangt elke '*' door W, V, A, L of D en houdt de startvoorraad 3/3/3/3/1 aan
+func expandOpenings(pattern string) []string {
+	openings := []string{""}
+	for i := 0; i < len(pattern); i++ {
+		var next []string
+		for _, opening := range openings {
+			for _, c := range depthToElement {
+				if pattern[i] != '*' && pattern[i] != c {
+					continue
+				}
+				used := strings.Count(opening, string(c)) + 1
+				if (c == 'D' && used > 1) || used > 3 {
+					continue
+				}
+				next = append(next, opening+string(c))
+			}
+		}
+		openings = next
+	}
+	return openings
+}
+
+// **firstDepthDigits** geeft de cijfers uit digits die op de eerste positie mogen staan (1-5)
+func firstDepthDigits(digits string) string {
+	var first []byte
+	for _, c := range []byte(digits) {
+		if c >= '1' && c <= '5' {
+			first = append(first, c)
+		}
+	}
+	return string(first)
+}
+
+// **randomDepthCode** maakt een willekeurige 12-cijferige code uit de gegeven cijfers (eerste positie 1-5, max 1 '5')
+func randomDepthCode(digits string, rng *rand.Rand) string {
+	code := make([]byte, 12)
+	for i := range code {
+		code[i] = digits[rng.Intn(len(digits))]
+	}
+	first := firstDepthDigits(digits)
+	code[0] = first[rng.Intn(len(first))]
+	return repairDepthCode(code, digits, rng)
+}
+
+// **repairDepthCode** herstelt een code na crossover of mutatie: eerste positie 1-5 en hoogstens één '5'.
+// digits moet minstens één cijfer 1-5 bevatten.
+func repairDepthCode(code []byte, digits string, rng *rand.Rand) string {
+	first := firstDepthDigits(digits)
+	if code[0] < '1' || code[0] > '5' {
+		code[0] = first[rng.Intn(len(first))]
+	}
+	var fives []int
+	for i, c := range code {
+		if c == '5' {
+			fives = append(fives, i)
+		}
+	}
+	if len(fives) > 1 {
+		keep := fives[rng.Intn(len(fives))]
+		if strings.Trim(first, "5") == "" {
+			keep = 0 // de eerste positie kan alleen een '5' zijn
+		}
+		for _, i := range fives {
+			switch {
+			case i == keep:
+			case i == 0:
+				for code[i] == '5' {
+					code[i] = first[rng.Intn(len(first))]
+				}
+			default:
+				for code[i] == '5' {
+					code[i] = digits[rng.Intn(len(digits))]
+				}
+			}
+		}
+	}
+	return string(code)
+}
+
+// **depthNeighbours** geeft alle codes op Hamming-afstand 1 (en met double ook 2) die de regels respecteren:
+// eerste positie 1-5 en hoogstens één '5'
+func depthNeighbours(engine string, digits string, double bool) []string {
+	var neighbours []string
+	valid := func(code []byte) bool {
+		return code[0] <= '5' && strings.Count(string(code), "5") <= 1
+	}
+	code := []byte(engine)
+	for i := 0; i < len(code); i++ {
+		original := code[i]
+		for _, d := range []byte(digits) {
+			if d == original {
+				continue
+			}
+			code[i] = d
+			if valid(code) {
+				neighbours = append(neighbours, string(code))
+			}
+			if double {
+				for j := i + 1; j < len(code); j++ {
+					second := code[j]
+					for _, e := range []byte(digits) {
+						if e == second {
+							continue
+						}
+						code[j] = e
+						if valid(code) {
+							neighbours = append(neighbours, string(code))
+						}
+					}
+					code[j] = second
+				}
+			}
+		}
+		code[i] = original
+	}
+	return neighbours
+}
+
+// **isValidDepthCode** controleert een diepte- of hybride code: een opening van letters gevolgd door cijfers 1-9,
+// met samen hoogstens één D en '5'
+func isValidDepthCode(engine string) bool {
+	if len(engine) != 12 {
+		return false
+	}
+	var used [5]int
+	i := 0
+	for ; i < len(engine) && isMoveLetter(engine[i]); i++ {
+		idx := moveToIndexArray[engine[i]]
+		used[idx]++
+		if used[idx] > 3 || used[4] > 1 {
+			return false
+		}
+	}
+	// Een '5' speelt D: hoogstens één in de code en niet naast een D in de opening
+	if used[4]+strings.Count(engine[i:], "5") > 1 {
+		return false
+	}
+	for ; i < len(engine); i++ {
+		if !isDepthDigit(engine[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// **isDepthDigit** geeft aan of een teken een dieptecijfer is (1-9, of 'a'-'d' voor eigen-relatieve dieptes)
+func isDepthDigit(c byte) bool {
+	return (c >= '1' && c <= '9') || (c >= 'a' && c <= 'd')
+}
+
+// Terugblik-codes: 'g' gevolgd door 12 paren (diepte 1-5, referentie). Referentie '1'-'4' = zet van de
+// tegenstander k beurten terug, 'e' = onze eigen vorige zet. Bestaat die zet nog niet, dan geldt de diepte
+// als vast element zoals bij de eerste zet. Cijfer 3 is dus "31", cijfer 8 is "32".
+const maxLookback = 4
+
+// **isLookbackCode** geeft aan of een code in de terugblik-notatie staat
+func isLookbackCode(engine string) bool {
+	return len(engine) == 25 && engine[0] == 'g'
+}
+
+// **isValidLookbackCode** controleert een terugblik-code
+func isValidLookbackCode(engine string) bool {
+	if !isLookbackCode(engine) {
+		return false
+	}
+	for i := 1; i < len(engine); i += 2 {
+		depth, ref := engine[i], engine[i+1]
+		if depth < '1' || depth > '5' {
+			return false
+		}
+		if ref != 'e' && (ref < '1' || ref > '0'+maxLookback) {
+			return false
+		}
+	}
+	return true
+}
+
+// **lookbackTarget** bepaalt het doelelement voor beurt i van een terugblik-code
+func lookbackTarget(engine string, i int, own, opp *[13]byte) byte {
+	depth := int(engine[1+2*i] - '0')
+	ref := engine[2+2*i]
+	var prev byte
+	if ref == 'e' {
+		if i >= 1 {
+			prev = own[i-1]
+		}
+	} else if k := int(ref - '0'); i >= k {
+		prev = opp[i-k]
+	}
+	if prev == 0 {
+		return getElementFromCode(depth)
+	}
+	return getElementByDepth(prev, depth)
+}
+
+// **lookbackOptions** geeft de zinvolle (diepte, referentie) paren voor positie i; equivalente paren
+// (bijv. elke referentie die nog niet bestaat) worden maar één keer opgenomen
+func lookbackOptions(i, maxBack int, includeOwn bool) []string {
+	var options []string
+	for depth := byte('1'); depth <= '4'; depth++ {
+		for k := 1; k <= maxBack && k <= i+1; k++ {
+			options = append(options, string([]byte{depth, byte('0' + k)}))
+		}
+		if includeOwn && i >= 1 {
+			options = append(options, string([]byte{depth, 'e'}))
+		}
+	}
+	return append(options, "51")
+}
+
+// **countLookbackEngines** telt de terugblik-codes die bij een prefix horen (max 1 '5')
+func countLookbackEngines(prefixLength int, hasUsedFive bool, maxBack int, includeOwn bool) int64 {
+	used, free := int64(1), int64(1) // aanvullingen vanaf positie i, met de '5' al gebruikt of nog vrij
+	for i := 11; i >= prefixLength; i-- {
+		n := int64(len(lookbackOptions(i, maxBack, includeOwn)) - 1)
+		used, free = n*used, n*free+used
+	}
+	if hasUsedFive {
+		return used
+	}
+	return free
+}
+
+// **generateLookbackEngines** genereert alle terugblik-codes bij een prefix, maar alleen als hun aantal
+// binnen het budget past; anders wordt enkel het aantal teruggegeven
+func generateLookbackEngines(startDepth string, maxBack int, includeOwn bool, policies string, budget int64) ([]string, int64) {
+	if !strings.HasPrefix(startDepth, "g") || len(startDepth)%2 != 1 || len(startDepth) > 25 {
+		return nil, 0
+	}
+	prefixLength := (len(startDepth) - 1) / 2
+	hasFive := false
+	for i := 0; i < prefixLength; i++ {
+		token := startDepth[1+2*i : 3+2*i]
+		valid := false
+		for _, option := range lookbackOptions(i, maxLookback, true) {
+			valid = valid || option == token
+		}
+		if !valid || (token[0] == '5' && hasFive) {
+			return nil, 0
+		}
+		hasFive = hasFive || token[0] == '5'
+	}
+
+	count := countLookbackEngines(prefixLength, hasFive, maxBack, includeOwn)
+	if policies != "" {
+		count *= int64(len(policies))
+	}
+	if count > budget {
+		return nil, count
+	}
+	engines := make([]string, 0, count)
+	generateLookbackRemaining(startDepth, prefixLength, hasFive, maxBack, includeOwn, policies, &engines)
+	return engines, count
+}
+
+// **generateLookbackRemaining** vult een terugblik-prefix recursief aan tot 12 posities
+func generateLookbackRemaining(prefix string, position int, hasUsedFive bool, maxBack int, includeOwn bool, policies string, engines *[]string) {
+	if position == 12 {
+		appendWithPolicies(prefix, policies, engines)
+		return
+	}
+	for _, option := range lookbackOptions(position, maxBack, includeOwn) {
+		if option[0] == '5' && hasUsedFive {
+			continue
+		}
+		generateLookbackRemaining(prefix+option, position+1, hasUsedFive || option[0] == '5', maxBack, includeOwn, policies, engines)
+	}
+}
+
+// **simulateDepthGameToMoves** genereert de zetten van een diepte-gebaseerde engine, reactief op de tegenstander
+func simulateDepthGameToMoves(engine string, opponent string) (moves [13]byte) {
+	if len(engine) != 12 || len(opponent) != 13 {
+		return
+	}
+
+	p := Player{
+		available: [5]int{3, 3, 3, 3, 1},
+	}
+	var opp [13]byte
+	copy(opp[:], opponent)
+
+	for i := 0; i < 12; i++ {
+		target := stepTarget(engine[i], i, &moves, &opp)
+		move := chooseAvailableElement(target, &p.available)
+		if move != 0 {
+			p.available[moveToIndexArray[move]]--
+			moves[p.moveCount] = move
+			p.moveCount++
+		} else {
+			move = getLastElement(&p.available)
+			if move != 0 {
+				p.available[moveToIndexArray[move]]--
+				moves[p.moveCount] = move
+				p.moveCount++
+			}
+		}
+	}
+
+	move := getLastElement(&p.available)
+	if move != 0 {
+		p.available[moveToIndexArray[move]]--
+		moves[p.moveCount] = move
+	} else {
+		moves[p.moveCount] = 'W'
+	}
+
+	return moves
+}
+
+// **isClassicCode** geeft aan of een code door de snelle simulaties (12 of 13 tekens) gespeeld kan worden
+func isClassicCode(engine string) bool {
+	return len(engine) == 12 || len(engine) == 13
+}
+
+// **simulateMatch** speelt één match met de snelste simulatie die bij beide codes past
+func simulateMatch(engine, inputEngine string) (p1Score, p2Score int) {
+	if !isClassicCode(engine) || !isClassicCode(inputEngine) {
+		return simulateGame(engine, inputEngine)
+	}
+	if len(inputEngine) == 13 {
+		if len(engine) == 12 {
+			p1Moves := simulateDepthGameToMoves(engine, inputEngine)
+			return simulateFixedGame(string(p1Moves[:]), inputEngine)
+		}
+		return simulateFixedGame(engine, inputEngine)
+	}
+	return simulateDepthGame(engine, inputEngine)
+}
+
+// **matchPoints** zet een uitslag om in punten: winst verschil + 10, verlies verschil - 10, gelijk p1Score
+func matchPoints(p1Score, p2Score int) int {
+	diff := p1Score - p2Score
+	if p1Score > p2Score {
+		return diff + 10 // Winst: +10 bonus
+	} else if p1Score < p2Score {
+		return diff - 10 // Verlies: -10 malus
+	}
+	return p1Score // Gelijkspel: +p1Score
+}
+
+// **scoreEngine** berekent de totaalscore van één engine tegen een pool, zoals evaluateBatch dat doet
+func scoreEngine(engine string, inputEngines []string) int {
+	totalScore := 0
+	for _, inputEngine := range inputEngines {
+		p1Score, p2Score := simulateMatch(engine, inputEngine)
+		if p1Score == -1 || p2Score == -1 {
+			continue
+		}
+		totalScore += matchPoints(p1Score, p2Score)
+	}
+	return totalScore
+}
+
+// **scoreEngines** berekent scoreEngine voor elke code, verdeeld over threads; de volgorde blijft behouden
+func scoreEngines(engines []string, inputEngines []string, numThreads int) []int {
+	scores := make([]int, len(engines))
+	var next int64 = -1
+	var wg sync.WaitGroup
+	for t := 0; t < numThreads; t++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(engines) {
+					return
+				}
+				scores[i] = scoreEngine(engines[i], inputEngines)
+			}
+		}()
+	}
+	wg.Wait()
+	return scores
+}
+
+// **playMatrix** speelt elke rij-engine tegen elke kolom-engine (rij als p1) en geeft per paar de punten van
+// beide kanten; ongeldige matches krijgen {-1, -1}. Met earlyStop stopt een spel zoals in evaluateBatch.
+func playMatrix(rows, cols []string, numThreads int, earlyStop bool) [][][2]int {
+	matrix := make([][][2]int, len(rows))
+	var next int64 = -1
+	var wg sync.WaitGroup
+	for t := 0; t < numThreads; t++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(rows) {
+					return
+				}
+				matrix[i] = make([][2]int, len(cols))
+				for j, col := range cols {
+					p1, p2, ok := playGame(rows[i], col, earlyStop)
+					if !ok {
+						matrix[i][j] = [2]int{-1, -1}
+						continue
+					}
+					matrix[i][j] = [2]int{p1.score, p2.score}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return matrix
+}
+
+// **matrixMagic** opent een binair matrixbestand. Daarna (little endian): uint32 rijen, uint32 kolommen, per
+// rij- en daarna kolomengine een uint8 lengte plus de code, en per cel (rij per rij) int16 punten p1, int16
+// punten p2 en int8 uitslag (1 winst, 0 gelijk, -1 verlies, -2 ongeldig)
+const matrixMagic = "BOTEMTX1"
+
+// **matrixOutcome** geeft de uitslag van een cel vanuit de rij-engine gezien (-2 = ongeldige match)
+func matrixOutcome(scores [2]int) int {
+	switch {
+	case scores[0] == -1:
+		return -2
+	case scores[0] > scores[1]:
+		return 1
+	case scores[0] < scores[1]:
+		return -1
+	}
+	return 0
+}
+
+// **writeMatrixCSV** schrijft één regel per cel: rij, kolom, punten van beide kanten, uitslag en de matchpunten
+// zoals evaluateBatch ze optelt
+func writeMatrixCSV(path string, rows, cols []string, matrix [][][2]int) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"engine", "opponent", "engine_points", "opponent_points", "outcome", "match_points"})
+	for i, row := range rows {
+		for j, col := range cols {
+			scores := matrix[i][j]
+			outcome := matrixOutcome(scores)
+			points := ""
+			if outcome != -2 {
+				points = strconv.Itoa(matchPoints(scores[0], scores[1]))
+			}
+			writer.Write([]string{row, col, strconv.Itoa(scores[0]), strconv.Itoa(scores[1]), strconv.Itoa(outcome), points})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// **writeMatrixBinary** schrijft de matrix in het compacte formaat van matrixMagic
+func writeMatrixBinary(path string, rows, cols []string, matrix [][][2]int) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	writer.WriteString(matrixMagic)
+	binary.Write(writer, binary.LittleEndian, [2]uint32{uint32(len(rows)), uint32(len(cols))})
+	for _, engines := range [][]string{rows, cols} {
+		for _, engine := range engines {
+			writer.WriteByte(byte(len(engine)))
+			writer.WriteString(engine)
+		}
+	}
+	for i := range rows {
+		for _, scores := range matrix[i] {
+			binary.Write(writer, binary.LittleEndian, [2]int16{int16(scores[0]), int16(scores[1])})
+			writer.WriteByte(byte(int8(matrixOutcome(scores))))
+		}
+	}
+	return writer.Flush()
+}
+
+// **solveMatrixGame** lost een nulsomspel op (rij maximaliseert) met de simplexmethode en geeft de optimale
+// gemengde strategieën van rij en kolom en de waarde van het spel
+func solveMatrixGame(payoff [][]float64) (rowMix, colMix []float64, value float64) {
+	m, n := len(payoff), len(payoff[0])
+	// Verschuif alle uitbetalingen naar > 0, zodat de waarde positief is
+	shift := 0.0
+	for _, row := range payoff {
+		for _, v := range row {
+			if 1-v > shift {
+				shift = 1 - v
+			}
+		}
+	}
+
+	// Kolomspeler: max sum(y) onder (A + shift) y <= 1, y >= 0; tableau met slackvariabelen
+	width := n + m + 1
+	tableau := make([][]float64, m+1)
+	for i := 0; i < m; i++ {
+		tableau[i] = make([]float64, width)
+		for j := 0; j < n; j++ {
+			tableau[i][j] = payoff[i][j] + shift
+		}
+		tableau[i][n+i] = 1
+		tableau[i][width-1] = 1
+	}
+	tableau[m] = make([]float64, width)
+	for j := 0; j < n; j++ {
+		tableau[m][j] = -1
+	}
+	basis := make([]int, m)
+	for i := range basis {
+		basis[i] = n + i
+	}
+
+	const eps = 1e-12
+	for {
+		// Regel van Bland: kleinste index met negatieve gereduceerde kost, voorkomt cycli
+		pivotCol := -1
+		for j := 0; j < width-1; j++ {
+			if tableau[m][j] < -eps {
+				pivotCol = j
+				break
+			}
+		}
+		if pivotCol == -1 {
+			break
+		}
+		pivotRow := -1
+		for i := 0; i < m; i++ {
+			if tableau[i][pivotCol] > eps {
+				ratio := tableau[i][width-1] / tableau[i][pivotCol]
+				if pivotRow == -1 {
+					pivotRow = i
+					continue
+				}
+				best := tableau[pivotRow][width-1] / tableau[pivotRow][pivotCol]
+				if ratio < best-eps || (ratio < best+eps && basis[i] < basis[pivotRow]) {
+					pivotRow = i
+				}
+			}
+		}
+		if pivotRow == -1 {
+			break // onbegrensd, kan niet bij positieve uitbetalingen
+		}
+		pivot := tableau[pivotRow][pivotCol]
+		for j := range tableau[pivotRow] {
+			tableau[pivotRow][j] /= pivot
+		}
+		for i := range tableau {
+			if i == pivotRow || tableau[i][pivotCol] == 0 {
+				continue
+			}
+			factor := tableau[i][pivotCol]
+			for j := range tableau[i] {
+				tableau[i][j] -= factor * tableau[pivotRow][j]
+			}
+		}
+		basis[pivotRow] = pivotCol
+	}
+
+	total := tableau[m][width-1]
+	rowMix = make([]float64, m)
+	colMix = make([]float64, n)
+	for i := 0; i < m; i++ {
+		rowMix[i] = math.Max(0, tableau[m][n+i]/total) // duale oplossing = strategie van de rijspeler
+		if basis[i] < n {
+			colMix[basis[i]] = tableau[i][width-1] / total
+		}
+	}
+	return rowMix, colMix, 1/total - shift
+}
+
+// Exacte oplosser: de uitbetaling hangt alleen af van de einduitslag, dus de waarde van een toestand hangt
+// alleen af van beide voorraden en het puntverschil; de rest van de geschiedenis is niet relevant. Elke toestand
+// is een gelijktijdig spel van hoogstens 5×5 zetten waarvan de uitkomsten de waarden van de volgende toestanden
+// zijn (backward induction).
+
+// **boteState** is een toestand voor de exacte oplosser, gezien vanuit de speler aan zet ("own")
+type boteState struct {
+	own, opp [5]int8
+	diff     int8
+}
+
+// **boteSolution** is de waarde van een toestand en de optimale gemengde strategie (W, V, A, L, D)
+type boteSolution struct {
+	value    float64
+	strategy [5]float64
+}
+
+// **boteSolver** onthoudt opgeloste toestanden; payoff is "result" (winst 1, gelijk 0, verlies -1) of "diff"
+type boteSolver struct {
+	payoff string
+	memo   map[boteState]boteSolution
+	mu     sync.RWMutex // alleen nodig voor lookup vanuit meerdere goroutines
+}
+
+// **newBoteSolver** maakt een oplosser voor de gegeven uitbetaling
+func newBoteSolver(payoff string) *boteSolver {
+	return &boteSolver{payoff: payoff, memo: make(map[boteState]boteSolution)}
+}
+
+// **startState** is de begintoestand met 3/3/3/3/1 voor beide spelers
+func startState() boteState {
+	start := [5]int8{3, 3, 3, 3, 1}
+	return boteState{own: start, opp: start}
+}
+
+// **solve** berekent de waarde en optimale strategie van een toestand
+func (s *boteSolver) solve(state boteState) boteSolution {
+	if solution, ok := s.memo[state]; ok {
+		return solution
+	}
+	var ownMoves, oppMoves []int
+	for i := 0; i < 5; i++ {
+		if state.own[i] > 0 {
+			ownMoves = append(ownMoves, i)
+		}
+		if state.opp[i] > 0 {
+			oppMoves = append(oppMoves, i)
+		}
+	}
+
+	var solution boteSolution
+	if len(ownMoves) == 0 || len(oppMoves) == 0 {
+		solution.value = float64(state.diff)
+		if s.payoff == "result" {
+			solution.value = float64(sign(int(state.diff)))
+		}
+		s.memo[state] = solution
+		return solution
+	}
+
+	payoff := make([][]float64, len(ownMoves))
+	for a, ownMove := range ownMoves {
+		payoff[a] = make([]float64, len(oppMoves))
+		for b, oppMove := range oppMoves {
+			next := state
+			next.own[ownMove]--
+			next.opp[oppMove]--
+			switch moveWins[ownMove][oppMove] {
+			case 1:
+				next.diff++
+			case 2:
+				next.diff--
+			}
+			payoff[a][b] = s.solve(next).value
+		}
+	}
+	rowMix, _, value := solveMatrixGame(payoff)
+	solution.value = value
+	for a, ownMove := range ownMoves {
+		solution.strategy[ownMove] = rowMix[a]
+	}
+	s.memo[state] = solution
+	return solution
+}
+
+// **lookup** geeft de oplossing van een toestand en is veilig voor gelijktijdig gebruik; een toestand die nog
+// niet opgelost is (bijv. tegen een vaste engine die zijn voorraad negeert) wordt onder een schrijfslot opgelost
+func (s *boteSolver) lookup(state boteState) boteSolution {
+	s.mu.RLock()
+	solution, ok := s.memo[state]
+	s.mu.RUnlock()
+	if ok {
+		return solution
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.solve(state)
+}
+
+// **sign** geeft -1, 0 of 1
+func sign(x int) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+// Optimale engines: "o" + seed speelt de optimale gemengde strategie van de exacte oplosser (uitbetaling
+// "result"). De kans wordt per beurt afgeleid uit de seed en de geschiedenis, dus een match is reproduceerbaar.
+var optimalSolver *boteSolver
+var optimalOnce sync.Once
+
+// **isOptimalCode** geeft aan of een code de optimale gemengde strategie speelt
+func isOptimalCode(engine string) bool {
+	return len(engine) >= 2 && engine[0] == 'o' && strings.Trim(engine[1:], "0123456789") == ""
+}
+
+// **playerState** zet de staat van twee spelers om naar een toestand van de oplosser, gezien vanuit own
+func playerState(own, opp *Player) boteState {
+	var state boteState
+	for i := 0; i < 5; i++ {
+		state.own[i] = int8(own.available[i])
+		state.opp[i] = int8(opp.available[i])
+	}
+	state.diff = int8(own.score - opp.score)
+	return state
+}
+
+// **optimalMove** trekt een zet uit de optimale strategie van de toestand
+func optimalMove(engine string, i int, own, opp *Player) byte {
+	optimalOnce.Do(func() {
+		solver := newBoteSolver("result")
+		solver.solve(startState()) // hele boom vooraf, zodat de memo daarna alleen gelezen wordt
+		optimalSolver = solver
+	})
+	strategy := optimalSolver.lookup(playerState(own, opp)).strategy
+
+	hash := fnv.New64a()
+	hash.Write([]byte(engine))
+	hash.Write(own.moves[:i])
+	hash.Write(opp.moves[:i])
+	r := float64(hash.Sum64()>>11) / float64(1<<53)
+	for idx, p := range strategy {
+		if p <= 0 {
+			continue
+		}
+		if r < p {
+			return depthToElement[idx]
+		}
+		r -= p
+	}
+	return getLastElement(&own.available)
+}
+
+// Beste antwoord: tegen een gewogen pool van deterministische tegenstanders waarvan we niet weten wie we treffen,
+// is de beste geschiedenisafhankelijke strategie een DP over informatietoestanden. Alle tegenstanders die nog
+// passen bij de waargenomen zetten hebben dezelfde zetten gespeeld, dus ook dezelfde voorraad en score; hun
+// volgende zet hangt (voor de ondersteunde notaties) alleen af van de beurt, de voorraden, de score en de laatste
+// paar zetten. Dat is de sleutel voor de memo.
+
+// **bestResponder** berekent het beste antwoord tegen een gewogen pool
+type bestResponder struct {
+	pool    []string
+	weights []float64
+	window  int // aantal laatste zetten waarvan de tegenstanders afhangen
+	memo    map[string]float64
+	single  map[string]float64 // toestanden met één tegenstander; wordt geleegd als hij te groot wordt
+	nodes   int
+}
+
+// **maxSingleMemo** begrenst het geheugen voor toestanden met één overgebleven tegenstander
+const maxSingleMemo = 4000000
+
+// **historyWindow** geeft het aantal laatste zetten waarvan de volgende zet van een ondersteunde code afhangt
+func historyWindow(engine string) int {
+	if len(engine) == 13 {
+		return 0
+	}
+	base, _ := splitPolicy(engine)
+	base, _ = splitInventoryFlags(base)
+	if isLookbackCode(base) {
+		return maxLookback
+	}
+	if strings.ContainsAny(base, "6789") {
+		return 2
+	}
+	return 1
+}
+
+// **supportsBestResponse** geeft aan of de volgende zet van een code alleen van de sleutel van de memo afhangt
+func supportsBestResponse(engine string) bool {
+	// Zelfde volgorde als engineMove: eindspel, dan policy, dan voorraadvlaggen
+	base, solve := splitEndgame(engine)
+	if solve > 0 {
+		return false
+	}
+	base, _ = splitPolicy(base)
+	base, _ = splitInventoryFlags(base)
+	return len(base) == 13 || isValidDepthCode(base) || isValidLookbackCode(base) || isValidConditionalCode(base)
+}
+
+// **responseKey** bouwt de memosleutel van een informatietoestand
+func responseKey(i, window int, us, them *Player, set []int) string {
+	key := make([]byte, 0, 32+2*len(set))
+	key = append(key, byte(i), byte(us.score), byte(them.score))
+	for idx := 0; idx < 5; idx++ {
+		key = append(key, byte(us.available[idx]), byte(them.available[idx]))
+	}
+	for j := i - window; j < i; j++ {
+		if j >= 0 {
+			key = append(key, us.moves[j], them.moves[j])
+		}
+	}
+	for _, o := range set {
+		key = append(key, byte(o>>8), byte(o))
+	}
+	return string(key)
+}
+
+// **value** geeft de hoogste gewogen totaalscore (matchPoints van volledige spellen) die vanaf beurt i haalbaar
+// is tegen de tegenstanders in set
+func (b *bestResponder) value(i int, us, them Player, set []int) float64 {
+	if i == 13 {
+		total := 0.0
+		points := float64(matchPoints(us.score, them.score))
+		for _, o := range set {
+			total += b.weights[o] * points
+		}
+		return total
+	}
+	key := responseKey(i, b.window, &us, &them, set)
+	memo := b.memo
+	if len(set) == 1 {
+		if len(b.single) > maxSingleMemo {
+			b.single = make(map[string]float64)
+		}
+		memo = b.single
+	}
+	if v, ok := memo[key]; ok {
+		return v
+	}
+	b.nodes++
+
+	groups := make(map[byte][]int)
+	var order []byte
+	for _, o := range set {
+		move := engineMove(b.pool[o], i, &them, &us)
+		if _, ok := groups[move]; !ok {
+			order = append(order, move)
+		}
+		groups[move] = append(groups[move], o)
+	}
+
+	// Bovengrens: alle resterende rondes winnen. Wordt die gehaald, dan kan geen andere zet beter zijn.
+	upper := 0.0
+	bestPoints := float64(matchPoints(us.score+13-i, them.score))
+	for _, o := range set {
+		upper += b.weights[o] * bestPoints
+	}
+
+	// Probeer eerst de zetten die nu het meeste gewicht verslaan, zodat de bovengrens vaak vroeg gehaald wordt
+	var moves []byte
+	gain := make(map[byte]float64)
+	for idx, move := range depthToElement {
+		if us.available[idx] == 0 || (i == 12 && move != getLastElement(&us.available)) {
+			continue
+		}
+		moves = append(moves, move)
+		for _, oppMove := range order {
+			if determineWinner(move, oppMove) == 1 {
+				for _, o := range groups[oppMove] {
+					gain[move] += b.weights[o]
+				}
+			}
+		}
+	}
+	sort.SliceStable(moves, func(a, c int) bool { return gain[moves[a]] > gain[moves[c]] })
+
+	best := math.Inf(-1)
+	for _, move := range moves {
+		if best >= upper {
+			break
+		}
+		total := 0.0
+		for _, oppMove := range order {
+			nextUs, nextThem := us, them
+			playMove(&nextUs, move)
+			playMove(&nextThem, oppMove)
+			switch determineWinner(move, oppMove) {
+			case 1:
+				nextUs.score++
+			case 2:
+				nextThem.score++
+			}
+			total += b.value(i+1, nextUs, nextThem, groups[oppMove])
+		}
+		if total > best {
+			best = total
+		}
+	}
+	memo[key] = best
+	return best
+}
+
+// **weightedFullScore** berekent de gewogen totaalscore van een engine met volledige spellen, vergelijkbaar
+// met de waarde van bestResponder
+func weightedFullScore(engine string, pool []string, weights []float64) float64 {
+	total := 0.0
+	for o, opponent := range pool {
+		p1, p2, ok := playGame(engine, opponent, false)
+		if ok {
+			total += weights[o] * float64(matchPoints(p1.score, p2.score))
+		}
+	}
+	return total
+}
+
+// **standing** houdt de toernooiresultaten van één engine bij
+type standing struct {
+	engine                   string
+	wins, draws, losses      int
+	pointsFor, pointsAgainst int
+	rating, ratingError      float64 // Elo en standaardfout
+}
+
+// **score** geeft de toernooiscore: winst 1, gelijkspel ½
+func (s *standing) score() float64 {
+	return float64(s.wins) + float64(s.draws)/2
+}
+
+// **recordGame** verwerkt de uitslag van één spel in de stand van beide engines
+func recordGame(a, b *standing, aScore, bScore int) {
+	a.pointsFor += aScore
+	a.pointsAgainst += bScore
+	b.pointsFor += bScore
+	b.pointsAgainst += aScore
+	switch {
+	case aScore > bScore:
+		a.wins++
+		b.losses++
+	case aScore < bScore:
+		a.losses++
+		b.wins++
+	default:
+		a.draws++
+		b.draws++
+	}
+}
+
+// **fitRatings** schat Bradley-Terry sterktes uit paarsgewijze resultaten (wins[i][j] = score van i tegen j,
+// gelijkspel telt ½) met het MM-algoritme en zet ze om naar Elo (gemiddelde 1500) met standaardfout. Elke
+// engine krijgt een virtueel gelijkspel tegen een gemiddelde tegenstander, zodat 0% of 100% eindig blijft.
+func fitRatings(wins [][]float64, standings []standing) {
+	n := len(standings)
+	strength := make([]float64, n)
+	for i := range strength {
+		strength[i] = 1
+	}
+	for it := 0; it < 1000; it++ {
+		maxChange := 0.0
+		next := make([]float64, n)
+		for i := 0; i < n; i++ {
+			won, denom := 0.5, 1/(strength[i]+1)
+			for j := 0; j < n; j++ {
+				if games := wins[i][j] + wins[j][i]; i != j && games > 0 {
+					won += wins[i][j]
+					denom += games / (strength[i] + strength[j])
+				}
+			}
+			next[i] = won / denom
+		}
+		// Normaliseer op geometrisch gemiddelde 1
+		logMean := 0.0
+		for _, v := range next {
+			logMean += math.Log(v) / float64(n)
+		}
+		for i := range next {
+			next[i] /= math.Exp(logMean)
+			maxChange = math.Max(maxChange, math.Abs(math.Log(next[i]/strength[i])))
+		}
+		strength = next
+		if maxChange < 1e-9 {
+			break
+		}
+	}
+	scale := 400 / math.Ln10
+	for i := 0; i < n; i++ {
+		information := strength[i] / ((strength[i] + 1) * (strength[i] + 1))
+		for j := 0; j < n; j++ {
+			if games := wins[i][j] + wins[j][i]; i != j && games > 0 {
+				information += games * strength[i] * strength[j] / ((strength[i] + strength[j]) * (strength[i] + strength[j]))
+			}
+		}
+		standings[i].rating = 1500 + scale*math.Log(strength[i])
+		standings[i].ratingError = scale / math.Sqrt(information)
+	}
+}
+
+// **writeStandings** schrijft de eindstand (gesorteerd op rating) naar stdout en een bestand
+func writeStandings(path string, standings []standing) error {
+	order := make([]int, len(standings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return standings[order[a]].rating > standings[order[b]].rating })
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(io.MultiWriter(file, os.Stdout))
+	for rank, i := range order {
+		s := &standings[i]
+		fmt.Fprintf(writer, "%3d. %s  W %d  G %d  V %d  score %.1f  punten %d-%d  Elo %.0f ± %.0f\n", rank+1, s.engine,
+			s.wins, s.draws, s.losses, s.score(), s.pointsFor, s.pointsAgainst, s.rating, 1.96*s.ratingError)
+	}
+	return writer.Flush()
+}
+
+// **playMatch** speelt een match van twee volledige spellen (beide zetvolgordes) en telt de punten per engine
+func playMatch(a, b string) (aPoints, bPoints int) {
+	if p1, p2, ok := playGame(a, b, false); ok {
+		aPoints += p1.score
+		bPoints += p2.score
+	}
+	if p1, p2, ok := playGame(b, a, false); ok {
+		aPoints += p2.score
+		bPoints += p1.score
+	}
+	return aPoints, bPoints
+}
+
+// **readResultFile** leest een resultatenbestand met "code (score: n)"; zonder score telt 0, de volgorde blijft
+func readResultFile(path string, limit int) ([]engineResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var results []engineResult
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() && (limit <= 0 || len(results) < limit) {
+		input := strings.TrimSpace(scanner.Text())
+		engine := parseEngineCode(input)
+		if input == "" || !isValidEngineCode(engine) {
+			continue
+		}
+		result := engineResult{engine: engine}
+		if idx := strings.Index(input, "(score: "); idx >= 0 {
+			fmt.Sscanf(input[idx+len("(score: "):], "%d", &result.score)
+		}
+		results = append(results, result)
+	}
+	return results, scanner.Err()
+}
+
+// **swissPlayer** houdt de stand van een deelnemer aan een Zwitsers toernooi bij
+type swissPlayer struct {
+	engine    string
+	seed      int // plaats in het resultatenbestand, 0 = sterkste
+	points    float64
+	buchholz  float64
+	pointsFor int
+	opponents map[int]bool
+	hadBye    bool
+}
+
+// **swissOrder** sorteert deelnemers op punten, dan Buchholz, dan seed (deterministisch)
+func swissOrder(players []swissPlayer) []int {
+	order := make([]int, len(players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := &players[order[a]], &players[order[b]]
+		if pa.points != pb.points {
+			return pa.points > pb.points
+		}
+		if pa.buchholz != pb.buchholz {
+			return pa.buchholz > pb.buchholz
+		}
+		return pa.seed < pb.seed
+	})
+	return order
+}
+
+// **swissPairings** koppelt van boven naar beneden zonder herhaalde ontmoetingen waar mogelijk; bij een oneven
+// aantal krijgt de laagst geplaatste speler zonder bye een bye (-1)
+func swissPairings(players []swissPlayer) [][2]int {
+	order := swissOrder(players)
+	var pairs [][2]int
+	if len(order)%2 == 1 {
+		for k := len(order) - 1; k >= 0; k-- {
+			if !players[order[k]].hadBye || k == 0 {
+				pairs = append(pairs, [2]int{order[k], -1})
+				order = append(order[:k:k], order[k+1:]...)
+				break
+			}
+		}
+	}
+	paired := make(map[int]bool)
+	for a, i := range order {
+		if paired[i] {
+			continue
+		}
+		partner := -1
+		for _, j := range order[a+1:] {
+			if !paired[j] && (partner == -1 || (!players[i].opponents[j] && players[i].opponents[partner])) {
+				partner = j
+				if !players[i].opponents[j] {
+					break
+				}
+			}
+		}
+		if partner == -1 {
+			continue
+		}
+		paired[i], paired[partner] = true, true
+		pairs = append(pairs, [2]int{i, partner})
+	}
+	return pairs
+}
+
+// **bracketOrder** geeft de standaard plaatsing van seeds in een schema van size (macht van 2): 1 tegen size,
+// 2 tegen size-1, enzovoort, zodat de sterkste seeds elkaar zo laat mogelijk treffen
+func bracketOrder(size int) []int {
+	order := []int{0}
+	for len(order) < size {
+		n := len(order) * 2
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n-1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// **evaluateBatch** evalueert een batch van engines en berekent de totale score met bonus/malus
+func evaluateBatch(engines []string, inputEngines []string, top10000Chan chan<- engineResult, progressComparisons *int64) {
+    h := &minHeap{}
+    heap.Init(h)
+    maxSize := 10000
+
+    for _, engine := range engines {
+        totalScore := 0
+        for _, inputEngine := range inputEngines {
+            p1Score, p2Score := simulateMatch(engine, inputEngine)
+            if p1Score == -1 || p2Score == -1 {
+                continue
+            }
+            totalScore += matchPoints(p1Score, p2Score)
+
+            // Update voortgang na elke match
+            atomic.AddInt64(progressComparisons, 1)
+            p := atomic.LoadInt64(progressComparisons)
+            if p % updateInterval == 0 {
+                printMu.Lock()
+                elapsed := time.Since(startTime).Seconds()
+                if elapsed > 0 {
+                    speed := float64(p) / elapsed / 1000 // k matches/s
+                    fmt.Printf("Voortgang: %d / %d matches (%.2f%%), Snelheid: %.1f k matches/s\n",
+                        p, totalComparisons, float64(p)/float64(totalComparisons)*100, speed)
+                } else {
+                    fmt.Printf("Voortgang: %d / %d matches (%.2f%%)\n",
+                        p, totalComparisons, float64(p)/float64(totalComparisons)*100)
+                }
+                printMu.Unlock()
+            }
+        }
+
+        result := engineResult{engine: engine, score: totalScore}
+        if h.Len() < maxSize {
+            heap.Push(h, result)
+        } else if totalScore > (*h)[0].score {
+            heap.Pop(h)
+            heap.Push(h, result)
+        }
+    }
+
+    for h.Len() > 0 {
+        top10000Chan <- heap.Pop(h).(engineResult)
+    }
+}
+
+// **parseEngineCode** haalt de engine code uit een invoer met prefix en kapt af na 12 cijfers voor depth engines
+func parseEngineCode(input string) string {
+	parts := strings.Split(input, ":")
+	engine := strings.TrimSpace(input)
+	if len(parts) > 2 {
+		engine = strings.TrimSpace(parts[2])
+	}
+	if idx := strings.IndexByte(engine, ' '); idx >= 0 {
+		engine = engine[:idx] // regels uit een resultatenbestand: "code (score: n)"
+	}
+	if len(engine) > 12 && strings.Trim(engine, "0123456789") == "" {
+		return engine[:12]
+	}
+	return engine
+}
+
+// **baseCode** geeft een code zonder eindspel, fallback-policy en voorraadvlaggen, in de volgorde van engineMove
+func baseCode(engine string) string {
+	engine, _ = splitEndgame(engine)
+	engine, _ = splitPolicy(engine)
+	engine, _ = splitInventoryFlags(engine)
+	return engine
+}
+
+// **isValidEngineCode** controleert of een code in één van de ondersteunde notaties staat
+func isValidEngineCode(engine string) bool {
+	// Optimale engines spelen een gemengde strategie; vlaggen, fallback en eindspel hebben daar geen betekenis
+	if isOptimalCode(baseCode(engine)) && !isOptimalCode(engine) {
+		return false
+	}
+	// Suffixen staan altijd in de volgorde "!vlaggen~p#k", de omgekeerde van waarin engineMove ze stript;
+	// een andere volgorde of een dubbele suffix zou stilzwijgend genegeerd worden en is dus ongeldig
+	if base, solve := splitEndgame(engine); solve > 0 {
+		return len(base) != 13 && !strings.Contains(base, "#") && isValidEngineCode(base)
+	}
+	if base, policy := splitPolicy(engine); policy != 0 {
+		return strings.IndexByte(fallbackPolicyCodes, policy) >= 0 && len(base) != 13 && !strings.ContainsAny(base, "~#") && isValidEngineCode(base)
+	}
+	if base, flags := splitInventoryFlags(engine); base != engine {
+		return flags != "" && strings.Trim(flags, inventoryFlagCodes) == "" && len(base) != 13 && !strings.ContainsAny(base, "~#") && isValidEngineCode(base)
+	}
+	validDepth := isValidDepthCode(engine) || isValidLookbackCode(engine) || isValidConditionalCode(engine) || isValidFSMCode(engine) || isOptimalCode(engine)
+	validFixed := len(engine) == 13 && strings.ContainsAny(engine, "WVALD") && !strings.ContainsAny(engine, "1234567890")
+	return validDepth || validFixed
+}
+
+// **readEngines** leest engine codes (één per regel) tot een lege regel, '.' of het einde van de invoer
+func readEngines(scanner *bufio.Scanner) []string {
+	var engines []string
+	for scanner.Scan() {
+		input := strings.TrimSpace(scanner.Text())
+		if input == "." || input == "" {
+			break
+		}
+		engine := parseEngineCode(input)
+		if isValidEngineCode(engine) {
+			engines = append(engines, engine)
+		} else {
+			fmt.Printf("Ongeldige engine code '%s'. Moet 12 chiffres (1-9, a-d), een hybride code (opening W/V/A/L/D + chiffres, 12 tekens) of 13 tekens (W, V, A, L, D) zijn, of een terugblik-code ('g' + 12 paren diepte/referentie) een conditionele code (codes gescheiden door '/') of een FSM-code ('f' + toestanden), optioneel met voorraadvlaggen '!wv', fallback-policy '~p' en eindspel '#k' (in die volgorde).\n", engine)
+		}
+	}
+	return engines
+}
+
+// **readEngineFile** leest engine codes uit een bestand, bijv. needFixesEngine.txt of een resultatenbestand
+func readEngineFile(path string, limit int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	var engines []string
+	for scanner.Scan() && (limit <= 0 || len(engines) < limit) {
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" {
+			continue
+		}
+		engine := parseEngineCode(input)
+		if isValidEngineCode(engine) {
+			engines = append(engines, engine)
+		}
+	}
+	return engines, scanner.Err()
+}
+
+// **readWeightedPool** leest een pool met gewichten uit regels "naam:gewicht:code" (zonder gewicht telt 1)
+func readWeightedPool(path string) ([]string, []float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	var engines []string
+	var weights []float64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		input := strings.TrimSpace(scanner.Text())
+		engine := parseEngineCode(input)
+		if input == "" || !isValidEngineCode(engine) {
+			continue
+		}
+		weight := 1.0
+		if parts := strings.Split(input, ":"); len(parts) > 2 {
+			if w, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err == nil && w >= 0 {
+				weight = w
+			}
+		}
+		engines = append(engines, engine)
+		weights = append(weights, weight)
+	}
+	return engines, weights, scanner.Err()
+}
+
+// **readNamedPool** leest een pool met namen uit regels "naam:gewicht:code"; zonder naam geldt de code als naam
+func readNamedPool(path string) ([]string, []string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	var names, engines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		input := strings.TrimSpace(scanner.Text())
+		engine := parseEngineCode(input)
+		if input == "" || !isValidEngineCode(engine) {
+			continue
+		}
+		name := engine
+		if parts := strings.Split(input, ":"); len(parts) > 2 {
+			name = strings.TrimSpace(parts[0])
+		}
+		names = append(names, name)
+		engines = append(engines, engine)
+	}
+	return names, engines, scanner.Err()
+}
+
+// **loadPool** leest de tegenstanders uit een bestand, of interactief van stdin als er geen pad is
+func loadPool(path string) []string {
+	if path != "" {
+		engines, err := readEngineFile(path, 0)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", path, err)
+		}
+		return engines
+	}
+	return loadPoolFrom("", bufio.NewScanner(os.Stdin))
+}
+
+// **loadPoolFrom** is loadPool met een bestaande scanner voor stdin, zodat een commando dat zelf al van stdin
+// leest geen regels verliest in de buffer van een tweede scanner
+func loadPoolFrom(path string, stdin *bufio.Scanner) []string {
+	if path != "" {
+		return loadPool(path)
+	}
+	fmt.Println("Voer engine codes in (één per regel, '.' om te stoppen):")
+	return readEngines(stdin)
+}
+
+// **evaluateEngines** verdeelt de kandidaten over threads en geeft de top 10.000 terug, beste eerst
+func evaluateEngines(candidates []string, inputEngines []string, numThreads int) []engineResult {
+	totalEngines := len(candidates)
+	totalComparisons = int64(totalEngines) * int64(len(inputEngines))
+	top10000Chan := make(chan engineResult, 1000000)
+	var wg sync.WaitGroup
+	progressComparisons = 0
+	startTime = time.Now()
+
+	if numThreads < 1 {
+		numThreads = runtime.NumCPU()
+	}
+	enginesPerThread := (totalEngines + numThreads - 1) / numThreads
+	for i := 0; i < numThreads; i++ {
+		start := i * enginesPerThread
+		end := start + enginesPerThread
+		if end > totalEngines {
+			end = totalEngines
+		}
+		if start >= end {
+			break
+		}
+		wg.Add(1)
+		go func(threadStart, threadEnd int) {
+			defer wg.Done()
+			batch := candidates[threadStart:threadEnd]
+			evaluateBatch(batch, inputEngines, top10000Chan, &progressComparisons)
+		}(start, end)
+	}
+
+	go func() {
+		wg.Wait()
+		close(top10000Chan)
+	}()
+
+	top10000 := &minHeap{}
+	heap.Init(top10000)
+	maxSize := 10000
+	for result := range top10000Chan {
+		if top10000.Len() < maxSize {
+			heap.Push(top10000, result)
+		} else if result.score > (*top10000)[0].score {
+			heap.Pop(top10000)
+			heap.Push(top10000, result)
+		}
+	}
+
+	results := make([]engineResult, top10000.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(top10000).(engineResult)
+	}
+	return results
+}
+
+// **writeResults** schrijft resultaten in het formaat "code (score: n)", beste eerst
+func writeResults(path string, results []engineResult) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	for _, result := range results {
+		if _, err := fmt.Fprintf(writer, "%s (score: %d)\n", result.engine, result.score); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// **runCommand** voert een subcommando uit (zonder argumenten start de interactieve zoektocht)
+func runCommand(command string, args []string) {
+	switch command {
+	case "conditional":
+		runConditional(args)
+	case "fsm":
+		runFSM(args)
+	case "inventory":
+		runInventory(args)
+	case "endgame":
+		runEndgame(args)
+	case "stochastic":
+		runStochastic(args)
+	case "genetic":
+		runGenetic(args)
+	case "climb":
+		runClimb(args)
+	case "fixes":
+		runFixes(args)
+	case "nash":
+		runNash(args)
+	case "solve":
+		runSolve(args)
+	case "bestresponse":
+		runBestResponse(args)
+	case "tournament":
+		runTournament(args)
+	case "swiss":
+		runSwiss(args)
+	case "knockout":
+		runKnockout(args)
+	case "matrix":
+		runMatrix(args)
+	case "breakdown":
+		runBreakdown(args)
+	case "explain":
+		runExplain(args)
+	case "record":
+		runRecord(args)
+	case "humans":
+		runHumans(args)
+	case "infer":
+		runInfer(args)
+	case "advise":
+		runAdvise(args)
+	case "play":
+		runPlay(args)
+	default:
+		fmt.Printf("Onbekend commando '%s'. Beschikbaar: conditional, fsm, inventory, endgame, stochastic, genetic, climb, fixes, nash, solve, bestresponse, tournament, swiss, knockout, matrix, breakdown, explain, record, humans, infer, advise, play.\n", command)
+	}
+}
+
+// **runConditional** evalueert conditionele engines opgebouwd uit de beste engines van een resultatenbestand
+func runConditional(args []string) {
+	fs := flag.NewFlagSet("conditional", flag.ExitOnError)
+	candidatesPath := fs.String("candidates", "top_10000_engines.txt", "resultatenbestand met kandidaat-codes")
+	top := fs.Int("top", 40, "aantal kandidaten uit het bestand")
+	branches := fs.Int("branches", 2, "aantal takken: 2 (niet voor/voor) of 3 (achter/gelijk/voor)")
+	poolPath := fs.String("pool", "", "bestand met tegenstanders (leeg = interactief invoeren)")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	outPath := fs.String("out", "top_conditional_engines.txt", "uitvoerbestand")
+	fs.Parse(args)
+
+	if *branches != 2 && *branches != 3 {
+		fmt.Println("Ongeldig aantal takken, moet 2 of 3 zijn.")
+		return
+	}
+	candidates, err := readEngineFile(*candidatesPath, 0)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *candidatesPath, err)
+		return
+	}
+	var depthCandidates []string
+	for _, candidate := range candidates {
+		if isValidDepthCode(candidate) && len(depthCandidates) < *top {
+			depthCandidates = append(depthCandidates, candidate)
+		}
+	}
+	inputEngines := loadPool(*poolPath)
+	if len(depthCandidates) == 0 || len(inputEngines) == 0 {
+		fmt.Println("Geen kandidaten of tegenstanders. Gestopt.")
+		return
+	}
+
+	engines := append(generateConditionalEngines(depthCandidates, *branches), depthCandidates...)
+	fmt.Printf("%d conditionele engines (plus %d gewone) tegen %d tegenstanders.\n", len(engines)-len(depthCandidates), len(depthCandidates), len(inputEngines))
+	results := evaluateEngines(engines, inputEngines, *threads)
+	if err := writeResults(*outPath, results); err != nil {
+		fmt.Printf("Fout bij het schrijven: %v\n", err)
+		return
+	}
+	fmt.Printf("Resultaten opgeslagen in %s uit %d matches.\n", *outPath, totalComparisons)
+}
+
+// **runFSM** zoekt de beste toestandsmachines: exhaustief als de familie binnen het budget past, anders
+// met hill climbing vanuit willekeurige starts. Met 15 invoersymbolen past in de praktijk alleen 1 toestand
+// (5 machines met een vaste diepte); 2 toestanden zijn al 5^2 × 2^30 ≈ 2,7·10^10 machines, dus vanaf 2
+// toestanden wordt altijd hill climbing gebruikt.
+func runFSM(args []string) {
+	fs := flag.NewFlagSet("fsm", flag.ExitOnError)
+	states := fs.Int("states", 1, "aantal toestanden (1-10); 1 wordt exhaustief doorzocht, vanaf 2 altijd hill climbing")
+	budget := fs.Int64("budget", 5000000, "maximaal aantal FSM's voor exhaustief zoeken")
+	restarts := fs.Int("restarts", 64, "aantal willekeurige starts voor hill climbing")
+	iterations := fs.Int("iterations", 2000, "aantal stappen per start")
+	seed := fs.Int64("seed", 1, "seed voor de willekeurige starts")
+	poolPath := fs.String("pool", "", "bestand met tegenstanders (leeg = interactief invoeren)")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	outPath := fs.String("out", "top_fsm_engines.txt", "uitvoerbestand")
+	fs.Parse(args)
+
+	if *states < 1 || *states > 10 {
+		fmt.Println("Ongeldig aantal toestanden, moet 1-10 zijn.")
+		return
+	}
+	inputEngines := loadPool(*poolPath)
+	if len(inputEngines) == 0 {
+		fmt.Println("Geen tegenstanders ingevoerd. Gestopt.")
+		return
+	}
+
+	var results []engineResult
+	if count := countFSMs(*states, *budget); count <= *budget {
+		fmt.Printf("Exhaustief: %d FSM's met %d toestanden tegen %d tegenstanders.\n", count, *states, len(inputEngines))
+		results = evaluateEngines(generateFSMs(*states), inputEngines, *threads)
+	} else {
+		fmt.Printf("Meer dan %d FSM's; hill climbing met %d starts van %d stappen (seed %d).\n", *budget, *restarts, *iterations, *seed)
+		results = make([]engineResult, *restarts)
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, *threads)
+		for r := 0; r < *restarts; r++ {
+			wg.Add(1)
+			go func(r int) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				results[r] = climbFSM(*states, *iterations, inputEngines, rand.New(rand.NewSource(*seed+int64(r))))
+			}(r)
+		}
+		wg.Wait()
+		sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	}
+
+	if err := writeResults(*outPath, results); err != nil {
+		fmt.Printf("Fout bij het schrijven: %v\n", err)
+		return
+	}
+	if len(results) > 0 {
+		fmt.Printf("Beste FSM: %s (score: %d). Resultaten opgeslagen in %s.\n", results[0].engine, results[0].score, *outPath)
+	}
+}
+
+// **runInventory** vergelijkt engines met en zonder voorraadvlaggen tegen een pool
+func runInventory(args []string) {
+	fs := flag.NewFlagSet("inventory", flag.ExitOnError)
+	startDepth := fs.String("start", "", "startdepth voor de gegenereerde familie (leeg = kandidaten uit -candidates)")
+	candidatesPath := fs.String("candidates", "top_10000_engines.txt", "resultatenbestand met kandidaat-codes")
+	top := fs.Int("top", 1000, "aantal kandidaten uit het bestand")
+	poolPath := fs.String("pool", "", "bestand met tegenstanders (leeg = interactief invoeren)")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	outPath := fs.String("out", "top_inventory_engines.txt", "uitvoerbestand")
+	fs.Parse(args)
+
+	var bases []string
+	if *startDepth != "" {
+		bases = generateEngines(*startDepth, classicDepthDigits, "")
+	} else {
+		candidates, err := readEngineFile(*candidatesPath, *top)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *candidatesPath, err)
+			return
+		}
+		for _, candidate := range candidates {
+			if len(candidate) != 13 && !strings.Contains(candidate, "!") {
+				bases = append(bases, candidate)
+			}
+		}
+	}
+	inputEngines := loadPool(*poolPath)
+	if len(bases) == 0 || len(inputEngines) == 0 {
+		fmt.Println("Geen kandidaten of tegenstanders. Gestopt.")
+		return
+	}
+
+	var engines []string
+	for _, base := range bases {
+		code, policy := splitPolicy(base)
+		suffix := ""
+		if policy != 0 {
+			suffix = "~" + string(policy)
+		}
+		engines = append(engines, base, code+"!w"+suffix, code+"!v"+suffix, code+"!wv"+suffix, code+"!vw"+suffix)
+	}
+	fmt.Printf("%d engines (%d basiscodes × 5 varianten) tegen %d tegenstanders.\n", len(engines), len(bases), len(inputEngines))
+	results := evaluateEngines(engines, inputEngines, *threads)
+	if err := writeResults(*outPath, results); err != nil {
+		fmt.Printf("Fout bij het schrijven: %v\n", err)
+		return
+	}
+	fmt.Printf("Resultaten opgeslagen in %s uit %d matches.\n", *outPath, totalComparisons)
+}
+
+// **runEndgame** meet per engine hoeveel een exact opgelost eindspel van k beurten oplevert tegen een pool
+func runEndgame(args []string) {
+	fs := flag.NewFlagSet("endgame", flag.ExitOnError)
+	candidatesPath := fs.String("candidates", "top_10000_engines.txt", "resultatenbestand met kandidaat-codes")
+	top := fs.Int("top", 10, "aantal kandidaten uit het bestand")
+	maxK := fs.Int("max", 6, "grootste aantal opgeloste slotbeurten (1-9)")
+	poolPath := fs.String("pool", "", "bestand met tegenstanders (leeg = interactief invoeren)")
+	fs.Parse(args)
+
+	if *maxK < 1 || *maxK > 9 {
+		fmt.Println("Ongeldige waarde voor -max, moet 1-9 zijn.")
+		return
+	}
+	candidates, err := readEngineFile(*candidatesPath, *top)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *candidatesPath, err)
+		return
+	}
+	inputEngines := loadPool(*poolPath)
+	if len(candidates) == 0 || len(inputEngines) == 0 {
+		fmt.Println("Geen kandidaten of tegenstanders. Gestopt.")
+		return
+	}
+
+	for _, candidate := range candidates {
+		base, _ := splitEndgame(candidate)
+		if len(base) == 13 {
+			continue
+		}
+		baseScore := scoreEngine(base, inputEngines)
+		fmt.Printf("%s: basis %d", base, baseScore)
+		for k := 1; k <= *maxK; k++ {
+			score := scoreEngine(fmt.Sprintf("%s#%d", base, k), inputEngines)
+			fmt.Printf(", #%d %d (%+d)", k, score, score-baseScore)
+		}
+		fmt.Println()
+	}
+}
+
+// **runStochastic** rangschikt stochastische engines op verwachte score, met de variantie erbij
+func runStochastic(args []string) {
+	fs := flag.NewFlagSet("stochastic", flag.ExitOnError)
+	enginesPath := fs.String("engines", "", "bestand met stochastische codes (leeg = interactief invoeren)")
+	poolPath := fs.String("pool", "", "bestand met tegenstanders (leeg = interactief invoeren)")
+	limit := fs.Int("limit", 4096, "maximaal aantal bladeren voor exacte berekening")
+	samples := fs.Int("samples", 2000, "aantal Monte Carlo trekkingen per tegenstander")
+	seed := fs.Int64("seed", 1, "seed voor Monte Carlo")
+	outPath := fs.String("out", "top_stochastic_engines.txt", "uitvoerbestand")
+	fs.Parse(args)
+
+	var engines []string
+	stdin := bufio.NewScanner(os.Stdin)
+	scanner := stdin
+	if *enginesPath != "" {
+		file, err := os.Open(*enginesPath)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *enginesPath, err)
+			return
+		}
+		defer file.Close()
+		scanner = bufio.NewScanner(file)
+	} else {
+		fmt.Println("Voer stochastische codes in (één per regel, '.' om te stoppen):")
+	}
+	for scanner.Scan() {
+		input := strings.TrimSpace(scanner.Text())
+		if input == "." || input == "" {
+			if *enginesPath == "" {
+				break
+			}
+			continue
+		}
+		engine := parseEngineCode(input)
+		if _, _, ok := parseStochasticCode(engine); ok {
+			engines = append(engines, engine)
+		} else {
+			fmt.Printf("Ongeldige stochastische code '%s'. Verwacht 'p' + 12 posities gescheiden door '.', bijv. 1.5.2=50|3=50....\n", engine)
+		}
+	}
+	inputEngines := loadPoolFrom(*poolPath, stdin)
+	if len(engines) == 0 || len(inputEngines) == 0 {
+		fmt.Println("Geen engines of tegenstanders. Gestopt.")
+		return
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	results := make([]stochasticResult, 0, len(engines))
+	for _, engine := range engines {
+		results = append(results, expectedScore(engine, inputEngines, *limit, *samples, rng))
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].mean > results[j].mean })
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	for _, result := range results {
+		method := "exact"
+		if !result.exact {
+			method = "monte carlo"
+		}
+		line := fmt.Sprintf("%s (score: %.2f, variantie: %.2f, %s)\n", result.engine, result.mean, result.variance, method)
+		if _, err := file.WriteString(line); err != nil {
+			fmt.Printf("Fout bij het schrijven: %v\n", err)
+			return
+		}
+	}
+	fmt.Printf("%d stochastische engines gerangschikt in %s.\n", len(results), *outPath)
+}
+
+// **runGenetic** zoekt engine codes met een genetisch algoritme: toernooiselectie, uniforme crossover per
+// cijferpositie, mutatie per positie en elitisme. Met dezelfde seed is het resultaat reproduceerbaar.
+func runGenetic(args []string) {
+	fs := flag.NewFlagSet("genetic", flag.ExitOnError)
+	populationSize := fs.Int("population", 200, "grootte van de populatie")
+	generations := fs.Int("generations", 50, "aantal generaties")
+	elite := fs.Int("elite", 10, "aantal beste engines dat ongewijzigd doorgaat")
+	mutation := fs.Float64("mutation", 0.05, "mutatiekans per cijferpositie")
+	tournament := fs.Int("tournament", 3, "grootte van de toernooiselectie")
+	digits := fs.String("digits", classicDepthDigits, "toegestane cijfers, bijv. 123456789abcd")
+	initPath := fs.String("init", "", "resultatenbestand om de beginpopulatie mee te vullen")
+	seed := fs.Int64("seed", 1, "seed voor het algoritme")
+	poolPath := fs.String("pool", "", "bestand met tegenstanders (leeg = interactief invoeren)")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	outPath := fs.String("out", "top_genetic_engines.txt", "uitvoerbestand")
+	fs.Parse(args)
+
+	if *populationSize < 2 || *elite < 0 || *elite >= *populationSize || *tournament < 1 || strings.Trim(*digits, "123456789abcd") != "" || strings.Trim(*digits, "5") == "" || firstDepthDigits(*digits) == "" {
+		fmt.Println("Ongeldige parameters voor het genetisch algoritme.")
+		return
+	}
+	inputEngines := loadPool(*poolPath)
+	if len(inputEngines) == 0 {
+		fmt.Println("Geen tegenstanders ingevoerd. Gestopt.")
+		return
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	population := make([]string, 0, *populationSize)
+	if *initPath != "" {
+		seeds, err := readEngineFile(*initPath, *populationSize)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *initPath, err)
+			return
+		}
+		for _, engine := range seeds {
+			if len(engine) == 12 && strings.Trim(engine, *digits) == "" {
+				population = append(population, engine)
+			}
+		}
+	}
+	for len(population) < *populationSize {
+		population = append(population, randomDepthCode(*digits, rng))
+	}
+
+	fitness := make(map[string]int)
+	var best engineResult
+	for gen := 0; gen <= *generations; gen++ {
+		var unknown []string
+		for _, engine := range population {
+			if _, ok := fitness[engine]; !ok {
+				fitness[engine] = 0
+				unknown = append(unknown, engine)
+			}
+		}
+		for i, score := range scoreEngines(unknown, inputEngines, *threads) {
+			fitness[unknown[i]] = score
+		}
+		sort.SliceStable(population, func(i, j int) bool { return fitness[population[i]] > fitness[population[j]] })
+
+		total, unique := 0, make(map[string]bool)
+		for _, engine := range population {
+			total += fitness[engine]
+			unique[engine] = true
+		}
+		if gen == 0 || fitness[population[0]] > best.score {
+			best = engineResult{engine: population[0], score: fitness[population[0]]}
+		}
+		fmt.Printf("Generatie %d: beste %s (score: %d), gemiddeld %.1f, slechtste %d, uniek %d\n",
+			gen, population[0], fitness[population[0]], float64(total)/float64(len(population)), fitness[population[len(population)-1]], len(unique))
+		if gen == *generations {
+			break
+		}
+
+		pick := func() string {
+			winner := population[rng.Intn(len(population))]
+			for k := 1; k < *tournament; k++ {
+				if challenger := population[rng.Intn(len(population))]; fitness[challenger] > fitness[winner] {
+					winner = challenger
+				}
+			}
+			return winner
+		}
+		next := append([]string(nil), population[:*elite]...)
+		for len(next) < *populationSize {
+			mother, father := pick(), pick()
+			child := make([]byte, 12)
+			for i := range child {
+				child[i] = mother[i]
+				if rng.Intn(2) == 1 {
+					child[i] = father[i]
+				}
+				if rng.Float64() < *mutation {
+					child[i] = (*digits)[rng.Intn(len(*digits))]
+				}
+			}
+			next = append(next, repairDepthCode(child, *digits, rng))
+		}
+		population = next
+	}
+
+	unique := make(map[string]bool)
+	var results []engineResult
+	for _, engine := range population {
+		if !unique[engine] {
+			unique[engine] = true
+			results = append(results, engineResult{engine: engine, score: fitness[engine]})
+		}
+	}
+	if err := writeResults(*outPath, results); err != nil {
+		fmt.Printf("Fout bij het schrijven: %v\n", err)
+		return
+	}
+	fmt.Printf("Beste engine: %s (score: %d). Eindpopulatie opgeslagen in %s.\n", best.engine, best.score, *outPath)
+}
+
+// **climbEngine** verbetert een startcode met steepest-ascent hill climbing en geeft het pad van verbeteringen
+func climbEngine(engine string, inputEngines []string, digits string, double bool, numThreads int) []engineResult {
+	path := []engineResult{{engine: engine, score: scoreEngine(engine, inputEngines)}}
+	for {
+		current := path[len(path)-1]
+		neighbours := depthNeighbours(current.engine, digits, double)
+		best := current
+		for i, score := range scoreEngines(neighbours, inputEngines, numThreads) {
+			if score > best.score {
+				best = engineResult{engine: neighbours[i], score: score}
+			}
+		}
+		if best.engine == current.engine {
+			return path
+		}
+		path = append(path, best)
+	}
+}
+
+// **annealEngine** verbetert een startcode met simulated annealing; het pad bevat elke nieuwe beste code
+func annealEngine(engine string, inputEngines []string, digits string, double bool, iterations int, temperature, cooling float64, rng *rand.Rand) []engineResult {
+	current := engineResult{engine: engine, score: scoreEngine(engine, inputEngines)}
+	path := []engineResult{current}
+	single := depthNeighbours(engine, digits, false)
+	for it := 0; it < iterations && len(single) > 0; it++ {
+		neighbours := single
+		if double && rng.Intn(2) == 1 {
+			neighbours = depthNeighbours(current.engine, digits, true)
+		}
+		candidate := neighbours[rng.Intn(len(neighbours))]
+		score := scoreEngine(candidate, inputEngines)
+		delta := float64(score - current.score)
+		if delta >= 0 || rng.Float64() < math.Exp(delta/temperature) {
+			current = engineResult{engine: candidate, score: score}
+			single = depthNeighbours(current.engine, digits, false)
+			if current.score > path[len(path)-1].score {
+				path = append(path, current)
+			}
+		}
+		temperature *= cooling
+		if temperature < 1e-9 {
+			temperature = 1e-9
+		}
+	}
+	return path
+}
+
+// **runClimb** verfijnt startcodes (bijv. de top van fix_start_*.txt) door lokale zoektocht over buurcodes
+func runClimb(args []string) {
+	fs := flag.NewFlagSet("climb", flag.ExitOnError)
+	seedsPath := fs.String("seeds", "fix_start_1.txt", "resultatenbestand met startcodes")
+	top := fs.Int("top", 10, "aantal startcodes uit het bestand")
+	method := fs.String("method", "hill", "hill (hill climbing) of anneal (simulated annealing)")
+	double := fs.Bool("double", false, "ook buren met twee gewijzigde cijfers bekijken")
+	digits := fs.String("digits", classicDepthDigits, "toegestane cijfers, bijv. 123456789abcd")
+	iterations := fs.Int("iterations", 5000, "aantal stappen per startcode (anneal)")
+	temperature := fs.Float64("temperature", 50, "begintemperatuur (anneal)")
+	cooling := fs.Float64("cooling", 0.999, "afkoelfactor per stap (anneal)")
+	seed := fs.Int64("seed", 1, "seed (anneal)")
+	poolPath := fs.String("pool", "", "bestand met tegenstanders (leeg = interactief invoeren)")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	outPath := fs.String("out", "top_climb_engines.txt", "uitvoerbestand met de beste engines")
+	pathsPath := fs.String("paths", "climb_paths.txt", "uitvoerbestand met de paden van verbeteringen")
+	fs.Parse(args)
+
+	if (*method != "hill" && *method != "anneal") || strings.Trim(*digits, "123456789abcd") != "" || *digits == "" {
+		fmt.Println("Ongeldige parameters voor de lokale zoektocht.")
+		return
+	}
+	candidates, err := readEngineFile(*seedsPath, 0)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *seedsPath, err)
+		return
+	}
+	var seeds []string
+	for _, candidate := range candidates {
+		if len(seeds) < *top && len(candidate) == 12 && strings.Trim(candidate, *digits) == "" {
+			seeds = append(seeds, candidate)
+		}
+	}
+	inputEngines := loadPool(*poolPath)
+	if len(seeds) == 0 || len(inputEngines) == 0 {
+		fmt.Println("Geen startcodes of tegenstanders. Gestopt.")
+		return
+	}
+
+	paths := make([][]engineResult, len(seeds))
+	if *method == "hill" {
+		for i, engine := range seeds {
+			paths[i] = climbEngine(engine, inputEngines, *digits, *double, *threads)
+		}
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, *threads)
+		for i, engine := range seeds {
+			wg.Add(1)
+			go func(i int, engine string) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				rng := rand.New(rand.NewSource(*seed + int64(i)))
+				paths[i] = annealEngine(engine, inputEngines, *digits, *double, *iterations, *temperature, *cooling, rng)
+			}(i, engine)
+		}
+		wg.Wait()
+	}
+
+	file, err := os.Create(*pathsPath)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	seen := make(map[string]bool)
+	var results []engineResult
+	for _, path := range paths {
+		steps := make([]string, len(path))
+		for j, step := range path {
+			steps[j] = fmt.Sprintf("%s (score: %d)", step.engine, step.score)
+		}
+		line := strings.Join(steps, " -> ")
+		fmt.Println(line)
+		if _, err := file.WriteString(line + "\n"); err != nil {
+			fmt.Printf("Fout bij het schrijven: %v\n", err)
+			return
+		}
+		if best := path[len(path)-1]; !seen[best.engine] {
+			seen[best.engine] = true
+			results = append(results, best)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	if err := writeResults(*outPath, results); err != nil {
+		fmt.Printf("Fout bij het schrijven: %v\n", err)
+		return
+	}
+	fmt.Printf("Beste engines opgeslagen in %s, paden in %s.\n", *outPath, *pathsPath)
+}
+
+// **writePool** schrijft een pool in het formaat van needFixesEngine.txt ("fix0<n>:1:code")
+func writePool(path string, pool []string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	for i, engine := range pool {
+		if _, err := fmt.Fprintf(writer, "fix0%d:1:%s\n", i, engine); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// **runFixes** automatiseert het zoeken naar "fixes": zoek de beste engines tegen de pool, voeg de top N toe
+// aan de pool en herhaal, tot het maximum aantal rondes of tot de pool niet meer verandert
+func runFixes(args []string) {
+	fs := flag.NewFlagSet("fixes", flag.ExitOnError)
+	startDepth := fs.String("start", "", "startdepth van de familie die elke ronde doorzocht wordt (leeg = alle combinaties)")
+	rounds := fs.Int("rounds", 5, "maximaal aantal rondes")
+	add := fs.Int("add", 300, "aantal beste engines dat per ronde aan de pool wordt toegevoegd")
+	poolPath := fs.String("pool", "", "bestand met de beginpool (leeg = interactief invoeren)")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	prefix := fs.String("prefix", "fixes_round", "prefix van de uitvoerbestanden per ronde")
+	fs.Parse(args)
+
+	pool := loadPool(*poolPath)
+	if len(pool) == 0 {
+		fmt.Println("Geen tegenstanders ingevoerd. Gestopt.")
+		return
+	}
+	candidates := generateEngines(*startDepth, classicDepthDigits, "")
+	if len(candidates) == 0 {
+		fmt.Println("Ongeldige startdepth. Gestopt.")
+		return
+	}
+
+	inPool := make(map[string]bool)
+	for _, engine := range pool {
+		inPool[engine] = true
+	}
+	for round := 1; round <= *rounds; round++ {
+		results := evaluateEngines(candidates, pool, *threads)
+		enginesPath := fmt.Sprintf("%s_%02d_engines.txt", *prefix, round)
+		if err := writeResults(enginesPath, results); err != nil {
+			fmt.Printf("Fout bij het schrijven: %v\n", err)
+			return
+		}
+
+		added := 0
+		for _, result := range results {
+			if added == *add {
+				break
+			}
+			if !inPool[result.engine] {
+				inPool[result.engine] = true
+				pool = append(pool, result.engine)
+				added++
+			}
+		}
+		poolFile := fmt.Sprintf("%s_%02d_pool.txt", *prefix, round)
+		if err := writePool(poolFile, pool); err != nil {
+			fmt.Printf("Fout bij het schrijven: %v\n", err)
+			return
+		}
+		if len(results) > 0 {
+			fmt.Printf("Ronde %d: beste %s (score: %d), %d engines toegevoegd, pool %d. Opgeslagen in %s en %s.\n",
+				round, results[0].engine, results[0].score, added, len(pool), enginesPath, poolFile)
+		}
+		if added == 0 {
+			fmt.Println("De pool verandert niet meer. Gestopt.")
+			break
+		}
+	}
+}
+
+// **runNash** berekent het Nash-evenwicht (gemengde strategie) over een set engines via de volledige
+// onderlinge uitbetalingsmatrix
+func runNash(args []string) {
+	fs := flag.NewFlagSet("nash", flag.ExitOnError)
+	enginesPath := fs.String("engines", "", "bestand met engines voor de rijspeler (leeg = interactief invoeren)")
+	opponentsPath := fs.String("opponents", "", "bestand met engines voor de kolomspeler (leeg = dezelfde set)")
+	top := fs.Int("top", 0, "alleen de eerste n engines uit elk bestand (0 = alle)")
+	payoffKind := fs.String("payoff", "diff", "uitbetaling: diff (puntverschil) of result (winst 1, gelijk 0, verlies -1)")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	outPath := fs.String("out", "nash_mixture.txt", "uitvoerbestand met de evenwichtsmix")
+	fs.Parse(args)
+
+	if *payoffKind != "diff" && *payoffKind != "result" {
+		fmt.Println("Ongeldige uitbetaling, moet diff of result zijn.")
+		return
+	}
+	load := func(path string) []string {
+		if path == "" {
+			return loadPool("")
+		}
+		engines, err := readEngineFile(path, *top)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", path, err)
+		}
+		return engines
+	}
+	rows := load(*enginesPath)
+	cols := rows
+	if *opponentsPath != "" {
+		cols = load(*opponentsPath)
+	}
+	if len(rows) == 0 || len(cols) == 0 {
+		fmt.Println("Geen engines ingevoerd. Gestopt.")
+		return
+	}
+
+	matrix := playMatrix(rows, cols, *threads, false)
+	payoff := make([][]float64, len(rows))
+	for i := range matrix {
+		payoff[i] = make([]float64, len(cols))
+		for j, scores := range matrix[i] {
+			if scores[0] == -1 {
+				continue // ongeldige match telt als gelijkspel
+			}
+			diff := scores[0] - scores[1]
+			if *payoffKind == "result" {
+				switch {
+				case diff > 0:
+					diff = 1
+				case diff < 0:
+					diff = -1
+				}
+			}
+			payoff[i][j] = float64(diff)
+		}
+	}
+	rowMix, colMix, value := solveMatrixGame(payoff)
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	report := func(title string, engines []string, mix []float64) {
+		order := make([]int, len(engines))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return mix[order[a]] > mix[order[b]] })
+		fmt.Fprintln(file, title)
+		fmt.Println(title)
+		for _, i := range order {
+			if mix[i] < 1e-9 {
+				break
+			}
+			line := fmt.Sprintf("%s (kans: %.4f)", engines[i], mix[i])
+			fmt.Fprintln(file, line)
+			fmt.Println(line)
+		}
+	}
+	fmt.Fprintf(file, "Waarde van het spel: %.4f\n", value)
+	fmt.Printf("Waarde van het spel: %.4f (%d × %d engines, uitbetaling %s)\n", value, len(rows), len(cols), *payoffKind)
+	report("Evenwichtsmix rijspeler:", rows, rowMix)
+	if *opponentsPath != "" {
+		report("Evenwichtsmix kolomspeler:", cols, colMix)
+	}
+	fmt.Printf("Evenwicht opgeslagen in %s.\n", *outPath)
+}
+
+// **runSolve** berekent de exacte waarde van Bote onder de huidige regels en schrijft de optimale strategie
+func runSolve(args []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	payoffKind := fs.String("payoff", "result", "uitbetaling: result (winst 1, gelijk 0, verlies -1) of diff (puntverschil)")
+	outPath := fs.String("out", "bote_policy.txt", "uitvoerbestand met de optimale strategie per toestand (leeg = niet schrijven)")
+	poolPath := fs.String("pool", "", "optioneel bestand met tegenstanders om de optimale strategie tegen te spelen")
+	seeds := fs.Int("seeds", 20, "aantal seeds waarover de score tegen de pool gemiddeld wordt")
+	fs.Parse(args)
+
+	if *payoffKind != "result" && *payoffKind != "diff" {
+		fmt.Println("Ongeldige uitbetaling, moet result of diff zijn.")
+		return
+	}
+	solver := newBoteSolver(*payoffKind)
+	root := solver.solve(startState())
+	fmt.Printf("Waarde van Bote (%s): %.6f over %d toestanden.\n", *payoffKind, root.value, len(solver.memo))
+	fmt.Print("Optimale eerste zet:")
+	for idx, p := range root.strategy {
+		if p > 1e-9 {
+			fmt.Printf(" %c=%.4f", depthToElement[idx], p)
+		}
+	}
+	fmt.Println()
+
+	if *outPath != "" {
+		states := make([]boteState, 0, len(solver.memo))
+		for state := range solver.memo {
+			states = append(states, state)
+		}
+		sort.Slice(states, func(a, b int) bool { return stateKey(states[a]) > stateKey(states[b]) })
+		file, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+			return
+		}
+		defer file.Close()
+		writer := bufio.NewWriter(file)
+		for _, state := range states {
+			solution := solver.memo[state]
+			fmt.Fprintf(writer, "eigen %v tegen %v verschil %+d: waarde %.4f", state.own, state.opp, state.diff, solution.value)
+			for idx, p := range solution.strategy {
+				if p > 1e-9 {
+					fmt.Fprintf(writer, " %c=%.4f", depthToElement[idx], p)
+				}
+			}
+			fmt.Fprintln(writer)
+		}
+		if err := writer.Flush(); err != nil {
+			fmt.Printf("Fout bij het schrijven: %v\n", err)
+			return
+		}
+		fmt.Printf("Optimale strategie opgeslagen in %s.\n", *outPath)
+	}
+
+	if *poolPath != "" && *payoffKind != "result" {
+		fmt.Println("De optimale strategie wordt alleen bij -payoff result tegen de pool gespeeld.")
+	} else if *poolPath != "" {
+		inputEngines := loadPool(*poolPath)
+		optimalOnce.Do(func() { optimalSolver = solver })
+		total := 0
+		for seed := 1; seed <= *seeds; seed++ {
+			total += scoreEngine(fmt.Sprintf("o%d", seed), inputEngines)
+		}
+		fmt.Printf("Gemiddelde score van de optimale strategie tegen %d tegenstanders: %.1f\n", len(inputEngines), float64(total)/float64(*seeds))
+	}
+}
+
+// **stateKey** ordent toestanden van begin naar einde van het spel (meeste voorraad eerst)
+func stateKey(state boteState) int {
+	key := 0
+	for i := 0; i < 5; i++ {
+		key = key*4 + int(state.own[i])
+	}
+	for i := 0; i < 5; i++ {
+		key = key*4 + int(state.opp[i])
+	}
+	total := 0
+	for i := 0; i < 5; i++ {
+		total += int(state.own[i])
+	}
+	return (total*1<<20+key)*32 + int(state.diff) + 13
+}
+
+// **runBestResponse** berekent de hoogst haalbare score tegen een gewogen pool over alle geschiedenisafhankelijke
+// strategieën en vergelijkt die met de beste engines uit een resultatenbestand
+func runBestResponse(args []string) {
+	fs := flag.NewFlagSet("bestresponse", flag.ExitOnError)
+	poolPath := fs.String("pool", "needFixesEngine.txt", "bestand met tegenstanders (naam:gewicht:code)")
+	candidatesPath := fs.String("candidates", "top_10000_engines.txt", "resultatenbestand om mee te vergelijken (leeg = geen)")
+	top := fs.Int("top", 10, "aantal engines uit het resultatenbestand")
+	fs.Parse(args)
+
+	pool, weights, err := readWeightedPool(*poolPath)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *poolPath, err)
+		return
+	}
+	set := make([]int, 0, len(pool))
+	totalWeight := 0.0
+	for o, engine := range pool {
+		if !supportsBestResponse(engine) {
+			fmt.Printf("Tegenstander '%s' wordt overgeslagen: zijn zetten hangen van de volledige geschiedenis af.\n", engine)
+			continue
+		}
+		set = append(set, o)
+		totalWeight += weights[o]
+	}
+	if len(set) == 0 {
+		fmt.Println("Geen tegenstanders. Gestopt.")
+		return
+	}
+
+	responder := &bestResponder{pool: pool, weights: weights, memo: make(map[string]float64), single: make(map[string]float64)}
+	for _, o := range set {
+		if w := historyWindow(pool[o]); w > responder.window {
+			responder.window = w
+		}
+	}
+	var us, them Player
+	us.available = [5]int{3, 3, 3, 3, 1}
+	them.available = [5]int{3, 3, 3, 3, 1}
+	start := time.Now()
+	ceiling := responder.value(0, us, them, set)
+	fmt.Printf("Beste antwoord tegen %d tegenstanders (totaal gewicht %.1f): %.1f (%d informatietoestanden, %.1fs)\n",
+		len(set), totalWeight, ceiling, responder.nodes, time.Since(start).Seconds())
+
+	if *candidatesPath == "" {
+		return
+	}
+	candidates, err := readEngineFile(*candidatesPath, *top)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *candidatesPath, err)
+		return
+	}
+	active := make([]string, len(set))
+	activeWeights := make([]float64, len(set))
+	for k, o := range set {
+		active[k], activeWeights[k] = pool[o], weights[o]
+	}
+	for _, candidate := range candidates {
+		score := weightedFullScore(candidate, active, activeWeights)
+		fmt.Printf("%s: %.1f (%.1f onder het plafond)\n", candidate, score, ceiling-score)
+	}
+}
+
+// **runTournament** speelt een volledige competitie (elk paar in beide zetvolgordes) met kruistabel en ratings
+func runTournament(args []string) {
+	fs := flag.NewFlagSet("tournament", flag.ExitOnError)
+	enginesPath := fs.String("engines", "", "bestand met engines (leeg = interactief invoeren)")
+	top := fs.Int("top", 0, "alleen de eerste n engines uit het bestand (0 = alle)")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	crossPath := fs.String("cross", "tournament_crosstable.csv", "uitvoerbestand met de kruistabel")
+	outPath := fs.String("out", "tournament_standings.txt", "uitvoerbestand met de eindstand")
+	fs.Parse(args)
+
+	var engines []string
+	if *enginesPath != "" {
+		var err error
+		if engines, err = readEngineFile(*enginesPath, *top); err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *enginesPath, err)
+			return
+		}
+	} else {
+		engines = loadPool("")
+	}
+	if len(engines) < 2 {
+		fmt.Println("Minstens twee engines nodig. Gestopt.")
+		return
+	}
+
+	matrix := playMatrix(engines, engines, *threads, false)
+	standings := make([]standing, len(engines))
+	wins := make([][]float64, len(engines))
+	for i := range engines {
+		standings[i].engine = engines[i]
+		wins[i] = make([]float64, len(engines))
+	}
+	for i := range engines {
+		for j := range engines {
+			if i == j || matrix[i][j][0] == -1 {
+				continue
+			}
+			p1, p2 := matrix[i][j][0], matrix[i][j][1]
+			recordGame(&standings[i], &standings[j], p1, p2)
+			switch {
+			case p1 > p2:
+				wins[i][j]++
+			case p1 < p2:
+				wins[j][i]++
+			default:
+				wins[i][j] += 0.5
+				wins[j][i] += 0.5
+			}
+		}
+	}
+	fitRatings(wins, standings)
+
+	file, err := os.Create(*crossPath)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	header := append([]string{"engine"}, engines...)
+	writer.Write(append(header, "W", "G", "V", "punten voor", "punten tegen"))
+	for i := range engines {
+		row := []string{engines[i]}
+		for j := range engines {
+			if i == j {
+				row = append(row, "")
+				continue
+			}
+			// Beide spellen vanuit de rij-engine: eerst als p1, dan als p2
+			row = append(row, fmt.Sprintf("%d-%d %d-%d", matrix[i][j][0], matrix[i][j][1], matrix[j][i][1], matrix[j][i][0]))
+		}
+		s := standings[i]
+		writer.Write(append(row, strconv.Itoa(s.wins), strconv.Itoa(s.draws), strconv.Itoa(s.losses), strconv.Itoa(s.pointsFor), strconv.Itoa(s.pointsAgainst)))
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		fmt.Printf("Fout bij het schrijven: %v\n", err)
+		return
+	}
+
+	if err := writeStandings(*outPath, standings); err != nil {
+		fmt.Printf("Fout bij het schrijven: %v\n", err)
+		return
+	}
+	fmt.Printf("%d spellen gespeeld. Kruistabel in %s, eindstand in %s.\n", len(engines)*(len(engines)-1), *crossPath, *outPath)
+}
+
+// **runSwiss** speelt een Zwitsers toernooi met seeding uit een resultatenbestand; per ronde een stand
+func runSwiss(args []string) {
+	fs := flag.NewFlagSet("swiss", flag.ExitOnError)
+	enginesPath := fs.String("engines", "top_10000_engines.txt", "resultatenbestand voor deelnemers en seeding")
+	top := fs.Int("top", 0, "alleen de eerste n engines uit het bestand (0 = alle)")
+	rounds := fs.Int("rounds", 0, "aantal rondes (0 = log2 van het aantal deelnemers, naar boven afgerond)")
+	outPath := fs.String("out", "swiss_standings.txt", "uitvoerbestand met de stand per ronde")
+	fs.Parse(args)
+
+	seeds, err := readResultFile(*enginesPath, *top)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *enginesPath, err)
+		return
+	}
+	if len(seeds) < 2 {
+		fmt.Println("Minstens twee engines nodig. Gestopt.")
+		return
+	}
+	sort.SliceStable(seeds, func(i, j int) bool { return seeds[i].score > seeds[j].score })
+	if *rounds <= 0 {
+		*rounds = int(math.Ceil(math.Log2(float64(len(seeds)))))
+	}
+	players := make([]swissPlayer, len(seeds))
+	for i, seed := range seeds {
+		players[i] = swissPlayer{engine: seed.engine, seed: i, opponents: make(map[int]bool)}
+	}
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(io.MultiWriter(file, os.Stdout))
+	defer writer.Flush()
+
+	for round := 1; round <= *rounds; round++ {
+		fmt.Fprintf(writer, "Ronde %d:\n", round)
+		for _, pair := range swissPairings(players) {
+			a := &players[pair[0]]
+			if pair[1] == -1 {
+				a.points++
+				a.hadBye = true
+				fmt.Fprintf(writer, "  %s: bye\n", a.engine)
+				continue
+			}
+			b := &players[pair[1]]
+			aPoints, bPoints := playMatch(a.engine, b.engine)
+			a.opponents[pair[1]], b.opponents[pair[0]] = true, true
+			a.pointsFor += aPoints
+			b.pointsFor += bPoints
+			switch {
+			case aPoints > bPoints:
+				a.points++
+			case aPoints < bPoints:
+				b.points++
+			default:
+				a.points += 0.5
+				b.points += 0.5
+			}
+			fmt.Fprintf(writer, "  %s - %s: %d-%d\n", a.engine, b.engine, aPoints, bPoints)
+		}
+		for i := range players {
+			players[i].buchholz = 0
+			for j := range players[i].opponents {
+				players[i].buchholz += players[j].points
+			}
+		}
+		fmt.Fprintf(writer, "Stand na ronde %d:\n", round)
+		for rank, i := range swissOrder(players) {
+			p := &players[i]
+			fmt.Fprintf(writer, "%4d. %s  punten %.1f  Buchholz %.1f  spelpunten %d  seed %d\n", rank+1, p.engine, p.points, p.buchholz, p.pointsFor, p.seed+1)
+		}
+	}
+}
+
+// **runKnockout** speelt een afvaltoernooi (enkel of dubbel) met seeding uit een resultatenbestand. Een match is
+// twee spellen; bij gelijke punten gaat de hoogste seed door.
+func runKnockout(args []string) {
+	fs := flag.NewFlagSet("knockout", flag.ExitOnError)
+	enginesPath := fs.String("engines", "top_10000_engines.txt", "resultatenbestand voor deelnemers en seeding")
+	top := fs.Int("top", 64, "aantal engines uit het bestand (0 = alle)")
+	double := fs.Bool("double", false, "dubbele eliminatie (pas uitgeschakeld na twee verloren matches)")
+	outPath := fs.String("out", "knockout_results.txt", "uitvoerbestand met de resultaten per ronde")
+	fs.Parse(args)
+
+	seeds, err := readResultFile(*enginesPath, *top)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *enginesPath, err)
+		return
+	}
+	if len(seeds) < 2 {
+		fmt.Println("Minstens twee engines nodig. Gestopt.")
+		return
+	}
+	sort.SliceStable(seeds, func(i, j int) bool { return seeds[i].score > seeds[j].score })
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(io.MultiWriter(file, os.Stdout))
+	defer writer.Flush()
+
+	// play speelt een ronde binnen één schema; -1 is een lege plaats (bye). Geeft winnaars en verliezers terug.
+	play := func(bracket []int) (winners, losers []int) {
+		for k := 0; k+1 < len(bracket); k += 2 {
+			a, b := bracket[k], bracket[k+1]
+			if a == -1 || b == -1 {
+				if a == -1 {
+					a = b
+				}
+				if a != -1 {
+					winners = append(winners, a)
+					fmt.Fprintf(writer, "  %s: bye\n", seeds[a].engine)
+				}
+				continue
+			}
+			aPoints, bPoints := playMatch(seeds[a].engine, seeds[b].engine)
+			winner, loser := a, b
+			if bPoints > aPoints || (bPoints == aPoints && b < a) {
+				winner, loser = b, a
+			}
+			fmt.Fprintf(writer, "  (%d) %s - (%d) %s: %d-%d, door: %s\n", a+1, seeds[a].engine, b+1, seeds[b].engine, aPoints, bPoints, seeds[winner].engine)
+			winners = append(winners, winner)
+			losers = append(losers, loser)
+		}
+		if len(bracket)%2 == 1 {
+			winners = append(winners, bracket[len(bracket)-1])
+		}
+		return winners, losers
+	}
+
+	size := 1
+	for size < len(seeds) {
+		size *= 2
+	}
+	winnersBracket := make([]int, size)
+	for k, seed := range bracketOrder(size) {
+		winnersBracket[k] = -1
+		if seed < len(seeds) {
+			winnersBracket[k] = seed
+		}
+	}
+	// Dubbele eliminatie: in elke ronde spelen de overlevers van het verliezersschema eerst onderling tot er niet
+	// meer over zijn dan er nieuw uit het winnaarsschema vallen, en treffen ze daarna die nieuwkomers
+	var losersBracket []int
+	for round := 1; len(winnersBracket) > 1 || len(losersBracket) > 1; round++ {
+		var dropped []int
+		if len(winnersBracket) > 1 {
+			fmt.Fprintf(writer, "Ronde %d (winnaarsschema):\n", round)
+			winnersBracket, dropped = play(winnersBracket)
+		}
+		if !*double {
+			continue
+		}
+		for len(losersBracket) > 1 && len(losersBracket) > len(dropped) {
+			fmt.Fprintf(writer, "Ronde %d (verliezersschema, onderling):\n", round)
+			losersBracket, _ = play(losersBracket)
+		}
+		if len(losersBracket) == 0 {
+			losersBracket = dropped
+			continue
+		}
+		if len(dropped) > 0 {
+			// Nieuwkomers in omgekeerde volgorde, zodat wie elkaar net troffen elkaar niet meteen weer treffen
+			size := len(losersBracket)
+			if len(dropped) > size {
+				size = len(dropped)
+			}
+			bracket := make([]int, 0, 2*size)
+			for k := 0; k < size; k++ {
+				survivor, newcomer := -1, -1
+				if k < len(losersBracket) {
+					survivor = losersBracket[k]
+				}
+				if k < len(dropped) {
+					newcomer = dropped[len(dropped)-1-k]
+				}
+				bracket = append(bracket, survivor, newcomer)
+			}
+			fmt.Fprintf(writer, "Ronde %d (verliezersschema, tegen nieuwkomers):\n", round)
+			losersBracket, _ = play(bracket)
+		}
+	}
+
+	champion := winnersBracket[0]
+	if *double && len(losersBracket) == 1 {
+		fmt.Fprintln(writer, "Finale:")
+		finalists, _ := play([]int{winnersBracket[0], losersBracket[0]})
+		champion = finalists[0]
+		if champion != winnersBracket[0] {
+			// De winnaar van het winnaarsschema heeft nu pas zijn eerste verlies: een beslissende tweede finale
+			fmt.Fprintln(writer, "Finale (herkansing):")
+			finalists, _ = play([]int{winnersBracket[0], losersBracket[0]})
+			champion = finalists[0]
+		}
+	}
+	fmt.Fprintf(writer, "Winnaar: (%d) %s\n", champion+1, seeds[champion].engine)
+}
+
+// **runMatrix** speelt alle kandidaten tegen alle tegenstanders en schrijft de volledige matrix naar CSV en naar
+// het binaire formaat, zodat andere tools ze kunnen analyseren zonder opnieuw te simuleren
+func runMatrix(args []string) {
+	fs := flag.NewFlagSet("matrix", flag.ExitOnError)
+	enginesPath := fs.String("engines", "", "bestand met kandidaten (leeg = interactief invoeren)")
+	opponentsPath := fs.String("opponents", "", "bestand met tegenstanders (leeg = interactief invoeren)")
+	top := fs.Int("top", 0, "alleen de eerste n engines uit elk bestand (0 = alle)")
+	full := fs.Bool("full", false, "volledige spellen spelen in plaats van te stoppen zoals evaluateBatch")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	csvPath := fs.String("csv", "payoff_matrix.csv", "CSV-uitvoerbestand (leeg = niet schrijven)")
+	binPath := fs.String("bin", "payoff_matrix.bin", "binair uitvoerbestand (leeg = niet schrijven)")
+	fs.Parse(args)
+
+	stdin := bufio.NewScanner(os.Stdin) // één scanner voor beide lijsten, anders slokt de eerste de tweede op
+	load := func(path, what string) []string {
+		if path == "" {
+			fmt.Printf("Voer de %s in:\n", what)
+			return loadPoolFrom("", stdin)
+		}
+		engines, err := readEngineFile(path, *top)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", path, err)
+		}
+		return engines
+	}
+	rows := load(*enginesPath, "kandidaten")
+	cols := load(*opponentsPath, "tegenstanders")
+	if len(rows) == 0 || len(cols) == 0 {
+		fmt.Println("Geen engines ingevoerd. Gestopt.")
+		return
+	}
+
+	start := time.Now()
+	matrix := playMatrix(rows, cols, *threads, !*full)
+	fmt.Printf("%d x %d matches gespeeld in %v\n", len(rows), len(cols), time.Since(start))
+	if *csvPath != "" {
+		if err := writeMatrixCSV(*csvPath, rows, cols, matrix); err != nil {
+			fmt.Printf("Fout bij het schrijven van %s: %v\n", *csvPath, err)
+		} else {
+			fmt.Printf("Matrix geschreven naar %s\n", *csvPath)
+		}
+	}
+	if *binPath != "" {
+		if err := writeMatrixBinary(*binPath, rows, cols, matrix); err != nil {
+			fmt.Printf("Fout bij het schrijven van %s: %v\n", *binPath, err)
+		} else {
+			fmt.Printf("Matrix geschreven naar %s\n", *binPath)
+		}
+	}
+}
+
+// **opponentRecord** telt de resultaten van één engine tegen één benoemde tegenstander
+type opponentRecord struct {
+	name                 string
+	wins, draws, losses  int
+	pointsFor, pointsOpp int
+	matchPoints          int
+}
+
+// **runBreakdown** toont per engine uit een resultatenbestand de winst/gelijk/verlies tegen elke benoemde
+// tegenstander uit de pool, de zwakste matchups en het aantal verslagen tegenstanders
+func runBreakdown(args []string) {
+	fs := flag.NewFlagSet("breakdown", flag.ExitOnError)
+	enginesPath := fs.String("engines", "top_10000_engines.txt", "resultatenbestand met de gerangschikte engines")
+	top := fs.Int("top", 10, "aantal engines uit het bestand (0 = alle)")
+	poolPath := fs.String("pool", "needFixesEngine.txt", "pool met benoemde tegenstanders (naam:gewicht:code)")
+	weakest := fs.Int("weakest", 5, "aantal zwakste matchups per engine")
+	outPath := fs.String("out", "breakdown.txt", "uitvoerbestand")
+	fs.Parse(args)
+
+	if *weakest < 0 {
+		fmt.Println("Ongeldig aantal zwakste matchups, moet 0 of meer zijn.")
+		return
+	}
+
+	results, err := readResultFile(*enginesPath, *top)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *enginesPath, err)
+		return
+	}
+	names, pool, err := readNamedPool(*poolPath)
+	if err != nil {
+		fmt.Printf("Fout bij het lezen van %s: %v\n", *poolPath, err)
+		return
+	}
+	if len(results) == 0 || len(pool) == 0 {
+		fmt.Println("Geen engines of tegenstanders gevonden. Gestopt.")
+		return
+	}
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(io.MultiWriter(file, os.Stdout))
+	defer writer.Flush()
+
+	for _, result := range results {
+		// Tegenstanders met dezelfde naam worden samengeteld, in volgorde van eerste voorkomen
+		var records []*opponentRecord
+		byName := make(map[string]*opponentRecord)
+		total := 0
+		for k, opponent := range pool {
+			record := byName[names[k]]
+			if record == nil {
+				record = &opponentRecord{name: names[k]}
+				byName[names[k]] = record
+				records = append(records, record)
+			}
+			p1Score, p2Score := simulateMatch(result.engine, opponent)
+			if p1Score == -1 || p2Score == -1 {
+				continue
+			}
+			switch {
+			case p1Score > p2Score:
+				record.wins++
+			case p1Score < p2Score:
+				record.losses++
+			default:
+				record.draws++
+			}
+			record.pointsFor += p1Score
+			record.pointsOpp += p2Score
+			points := matchPoints(p1Score, p2Score)
+			record.matchPoints += points
+			total += points
+		}
+		beaten := 0
+		for _, record := range records {
+			if record.wins > record.losses {
+				beaten++
+			}
+		}
+		fmt.Fprintf(writer, "%s (score: %d) verslaat %d van %d tegenstanders\n", result.engine, total, beaten, len(records))
+		for _, record := range records {
+			fmt.Fprintf(writer, "  %-12s W %d  G %d  V %d  punten %d-%d  matchpunten %d\n", record.name, record.wins, record.draws, record.losses, record.pointsFor, record.pointsOpp, record.matchPoints)
+		}
+		sorted := append([]*opponentRecord(nil), records...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].matchPoints < sorted[j].matchPoints })
+		if len(sorted) > *weakest {
+			sorted = sorted[:*weakest]
+		}
+		weak := make([]string, len(sorted))
+		for k, record := range sorted {
+			weak[k] = fmt.Sprintf("%s (%d)", record.name, record.matchPoints)
+		}
+		fmt.Fprintf(writer, "  Zwakste matchups: %s\n\n", strings.Join(weak, ", "))
+	}
+}
+
+// **runExplain** toont beurt voor beurt hoe ENGINE tegen OPPONENT speelt
+func runExplain(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Gebruik: explain ENGINE OPPONENT")
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return
+	}
+	engine1, engine2 := parseEngineCode(fs.Arg(0)), parseEngineCode(fs.Arg(1))
+	for _, engine := range []string{engine1, engine2} {
+		if !isValidEngineCode(engine) {
+			fmt.Printf("Ongeldige engine code '%s'.\n", engine)
+			return
+		}
+	}
+	explainGame(engine1, engine2, os.Stdout)
+}
+
+// **runRecord** schrijft spelverslagen van ENGINE tegen OPPONENT, of van alle paren uit twee bestanden;
+// met -verify worden de verslagen in een bestand ingelezen, gecontroleerd en opnieuw geschreven ter vergelijking
+func runRecord(args []string) {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	enginesPath := fs.String("engines", "", "bestand met engines voor P1 (in plaats van ENGINE OPPONENT)")
+	opponentsPath := fs.String("opponents", "", "bestand met engines voor P2")
+	top := fs.Int("top", 0, "alleen de eerste n engines uit elk bestand (0 = alle)")
+	outPath := fs.String("out", "games.txt", "uitvoerbestand met de verslagen (- = standaarduitvoer)")
+	verifyPath := fs.String("verify", "", "verslagen uit dit bestand inlezen en controleren dat ze ongewijzigd terug te schrijven zijn")
+	fs.Usage = func() {
+		fmt.Println("Gebruik: record [-out bestand] ENGINE OPPONENT | record -engines bestand -opponents bestand | record -verify bestand")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *verifyPath != "" {
+		data, err := os.ReadFile(*verifyPath)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *verifyPath, err)
+			return
+		}
+		records, err := readGameRecords(strings.NewReader(string(data)))
+		if err != nil {
+			fmt.Printf("Fout in %s: %v\n", *verifyPath, err)
+			return
+		}
+		var b strings.Builder
+		for _, r := range records {
+			writeGameRecord(&b, r)
+		}
+		again, err := readGameRecords(strings.NewReader(b.String()))
+		same := err == nil && len(again) == len(records)
+		for k := 0; same && k < len(records); k++ {
+			same = fmt.Sprint(again[k]) == fmt.Sprint(records[k])
+		}
+		fmt.Printf("%d verslagen gelezen, terugschrijven en opnieuw lezen geeft hetzelfde resultaat: %v\n", len(records), same)
+		return
+	}
+
+	var rows, cols []string
+	if *enginesPath != "" {
+		var err error
+		if rows, err = readEngineFile(*enginesPath, *top); err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *enginesPath, err)
+			return
+		}
+		cols = rows
+		if *opponentsPath != "" {
+			if cols, err = readEngineFile(*opponentsPath, *top); err != nil {
+				fmt.Printf("Fout bij het lezen van %s: %v\n", *opponentsPath, err)
+				return
+			}
+		}
+	} else if fs.NArg() == 2 {
+		rows, cols = []string{parseEngineCode(fs.Arg(0))}, []string{parseEngineCode(fs.Arg(1))}
+	} else {
+		fs.Usage()
+		return
+	}
+	for _, engine := range append(append([]string(nil), rows...), cols...) {
+		if !isValidEngineCode(engine) {
+			fmt.Printf("Ongeldige engine code '%s'.\n", engine)
+			return
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "-" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+			return
+		}
+		defer file.Close()
+		out = file
+	}
+	writer := bufio.NewWriter(out)
+	defer writer.Flush()
+	count := 0
+	for _, row := range rows {
+		for _, col := range cols {
+			p1, p2, ok := playGame(row, col, false)
+			if !ok {
+				continue
+			}
+			writeGameRecord(writer, newGameRecord(row, col, p1, p2))
+			count++
+		}
+	}
+	if *outPath != "-" {
+		fmt.Printf("%d verslagen geschreven naar %s\n", count, *outPath)
+	}
+}
+
+// **humanScore** houdt bij hoe een kandidaat het doet tegen de opgenomen mensen
+type humanScore struct {
+	engine       string
+	humansBeaten int // mensen met meer gewonnen dan verloren lijnen
+	linesBeaten  int
+	points       int
+}
+
+// **better** ordent kandidaten: meeste verslagen mensen, dan lijnen, dan punten, dan de code (deterministisch)
+func (a humanScore) better(b humanScore) bool {
+	if a.humansBeaten != b.humansBeaten {
+		return a.humansBeaten > b.humansBeaten
+	}
+	if a.linesBeaten != b.linesBeaten {
+		return a.linesBeaten > b.linesBeaten
+	}
+	if a.points != b.points {
+		return a.points > b.points
+	}
+	return a.engine < b.engine
+}
+
+// **humanHeap** implementeert heap.Interface voor de beste kandidaten tegen mensen (slechtste bovenaan)
+type humanHeap []humanScore
+
+func (h humanHeap) Len() int           { return len(h) }
+func (h humanHeap) Less(i, j int) bool { return h[j].better(h[i]) }
+func (h humanHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *humanHeap) Push(x interface{}) {
+	*h = append(*h, x.(humanScore))
+}
+func (h *humanHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
+
+// **offer** houdt hoogstens size kandidaten bij
+func (h *humanHeap) offer(score humanScore, size int) {
+	if h.Len() < size {
+		heap.Push(h, score)
+	} else if size > 0 && score.better((*h)[0]) {
+		(*h)[0] = score
+		heap.Fix(h, 0)
+	}
+}
+
+// **runHumans** importeert spelverslagen en CSV-logs van mensen, maakt van elke lijn een vaste tegenstander en
+// rapporteert welke diepte-engines de meeste mensen verslaan, plus statistieken per mens
+func runHumans(args []string) {
+	fs := flag.NewFlagSet("humans", flag.ExitOnError)
+	recordsPath := fs.String("records", "", "bestanden met spelverslagen, gescheiden door komma's")
+	csvPath := fs.String("csv", "", "CSV-logs (naam, zetten), gescheiden door komma's")
+	side := fs.String("side", "auto", "menselijke kant in verslagen: auto (zonder Engine-kopregel), 1, 2 of both")
+	enginesPath := fs.String("engines", "", "bestand met kandidaat-engines (of -start)")
+	startDepth := fs.String("start", "", "startdiepte voor het genereren van kandidaten, zoals in de interactieve modus (of -engines)")
+	topN := fs.Int("top", 20, "aantal beste engines in het rapport")
+	threads := fs.Int("threads", runtime.NumCPU(), "aantal threads")
+	outPath := fs.String("out", "humans_report.txt", "uitvoerbestand")
+	fs.Parse(args)
+
+	var lines []humanLine
+	for _, path := range strings.Split(*recordsPath, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", path, err)
+			return
+		}
+		records, err := readGameRecords(file)
+		file.Close()
+		if err != nil {
+			fmt.Printf("Fout in %s: %v\n", path, err)
+			return
+		}
+		lines = append(lines, humanLinesFromRecords(records, *side)...)
+	}
+	for _, path := range strings.Split(*csvPath, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", path, err)
+			return
+		}
+		csvLines, skipped, err := humanLinesFromCSV(file)
+		file.Close()
+		if err != nil {
+			fmt.Printf("Fout in %s: %v\n", path, err)
+			return
+		}
+		if skipped > 0 {
+			fmt.Printf("%s: %d rijen zonder 13 geldige zetten overgeslagen\n", path, skipped)
+		}
+		lines = append(lines, csvLines...)
+	}
+	if len(lines) == 0 {
+		fmt.Println("Geen menselijke lijnen gevonden. Gestopt.")
+		return
+	}
+	var names []string
+	humanIndex := make(map[string]int)
+	lineHuman := make([]int, len(lines))
+	for k, line := range lines {
+		idx, ok := humanIndex[line.name]
+		if !ok {
+			idx = len(names)
+			humanIndex[line.name] = idx
+			names = append(names, line.name)
+		}
+		lineHuman[k] = idx
+	}
+
+	var candidates []string
+	switch {
+	case *enginesPath != "":
+		var err error
+		if candidates, err = readEngineFile(*enginesPath, 0); err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *enginesPath, err)
+			return
+		}
+	case *startDepth != "":
+		candidates = generateEngines(*startDepth, classicDepthDigits, "")
+	default:
+		fmt.Println("Geef kandidaten met -engines of een startdiepte met -start; de hele familie is te groot.")
+		return
+	}
+	if len(candidates) == 0 {
+		fmt.Println("Geen kandidaat-engines. Gestopt.")
+		return
+	}
+	fmt.Printf("%d kandidaten tegen %d lijnen van %d mensen.\n", len(candidates), len(lines), len(names))
+
+	// Per thread: de beste kandidaten en per mens het aantal kandidaten dat hem verslaat en de beste ertegen
+	type humanStat struct {
+		beatenBy   int
+		bestEngine string
+		bestPoints int
+	}
+	tops := make([]humanHeap, *threads)
+	stats := make([][]humanStat, *threads)
+	var next int64 = -1
+	var wg sync.WaitGroup
+	for t := 0; t < *threads; t++ {
+		stats[t] = make([]humanStat, len(names))
+		for h := range stats[t] {
+			stats[t][h].bestPoints = math.MinInt
+		}
+		wg.Add(1)
+		go func(local []humanStat, top *humanHeap) {
+			defer wg.Done()
+			balance := make([]int, len(names))
+			points := make([]int, len(names))
+			for {
+				c := int(atomic.AddInt64(&next, 1))
+				if c >= len(candidates) {
+					return
+				}
+				score := humanScore{engine: candidates[c]}
+				for h := range balance {
+					balance[h], points[h] = 0, 0
+				}
+				for k, line := range lines {
+					p1Score, p2Score := simulateMatch(candidates[c], line.moves)
+					if p1Score == -1 || p2Score == -1 {
+						continue
+					}
+					h := lineHuman[k]
+					if p1Score > p2Score {
+						score.linesBeaten++
+						balance[h]++
+					} else if p1Score < p2Score {
+						balance[h]--
+					}
+					matchScore := matchPoints(p1Score, p2Score)
+					score.points += matchScore
+					points[h] += matchScore
+				}
+				for h := range balance {
+					if balance[h] > 0 {
+						score.humansBeaten++
+						local[h].beatenBy++
+					}
+					if points[h] > local[h].bestPoints {
+						local[h].bestPoints, local[h].bestEngine = points[h], candidates[c]
+					}
+				}
+				top.offer(score, *topN)
+			}
+		}(stats[t], &tops[t])
+	}
+	wg.Wait()
+
+	var scores []humanScore
+	for t := range tops {
+		scores = append(scores, tops[t]...)
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].better(scores[j]) })
+
+	file, err := os.Create(*outPath)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(io.MultiWriter(file, os.Stdout))
+	defer writer.Flush()
+
+	fmt.Fprintf(writer, "Beste engines tegen %d mensen (%d lijnen):\n", len(names), len(lines))
+	for k := 0; k < len(scores) && k < *topN; k++ {
+		score := scores[k]
+		fmt.Fprintf(writer, "%s (score: %d) verslaat %d/%d mensen, %d/%d lijnen\n", score.engine, score.points, score.humansBeaten, len(names), score.linesBeaten, len(lines))
+	}
+
+	fmt.Fprintln(writer, "\nPer mens:")
+	for h, name := range names {
+		merged := humanStat{bestPoints: math.MinInt}
+		for t := range stats {
+			merged.beatenBy += stats[t][h].beatenBy
+			if stats[t][h].bestPoints > merged.bestPoints || (stats[t][h].bestPoints == merged.bestPoints && stats[t][h].bestEngine < merged.bestEngine) {
+				merged.bestPoints, merged.bestEngine = stats[t][h].bestPoints, stats[t][h].bestEngine
+			}
+		}
+		var counts [5]int
+		openings := make(map[string]int)
+		games := 0
+		for k, line := range lines {
+			if lineHuman[k] != h {
+				continue
+			}
+			games++
+			for i := 0; i < 13; i++ {
+				counts[moveToIndexArray[line.moves[i]]]++
+			}
+			openings[line.moves[:3]]++
+		}
+		opening, openingCount := "", 0
+		for o, n := range openings {
+			if n > openingCount || (n == openingCount && o < opening) {
+				opening, openingCount = o, n
+			}
+		}
+		total := float64(13 * games)
+		fmt.Fprintf(writer, "%s: %d lijnen, zetten W %.0f%% V %.0f%% A %.0f%% L %.0f%% D %.0f%%, meest gespeelde opening %s (%dx)\n",
+			name, games, 100*float64(counts[0])/total, 100*float64(counts[1])/total, 100*float64(counts[2])/total, 100*float64(counts[3])/total, 100*float64(counts[4])/total, opening, openingCount)
+		fmt.Fprintf(writer, "  verslagen door %d van %d kandidaten (%.1f%%), beste ertegen: %s (%d)\n",
+			merged.beatenBy, len(candidates), 100*float64(merged.beatenBy)/float64(len(candidates)), merged.bestEngine, merged.bestPoints)
+	}
+}
+
+// **runInfer** leidt de diepte-code van een tegenstander af uit waargenomen spellen en rangschikt de
+// kandidaten naar waarschijnlijkheid als er zetten ontbreken of verkeerd genoteerd zijn
+func runInfer(args []string) {
+	fs := flag.NewFlagSet("infer", flag.ExitOnError)
+	gamesText := fs.String("games", "", "spellen als ONZE/HUNNE, gescheiden door komma's ('?' = onbekende zet)")
+	recordsPath := fs.String("records", "", "bestand met spelverslagen")
+	side := fs.Int("side", 2, "kant van de tegenstander in de verslagen (1 of 2)")
+	ownDigits := fs.Bool("own", false, "ook eigen-relatieve dieptes (a-d) overwegen")
+	maxErrors := fs.Int("errors", 0, "maximaal aantal afwijkende zetten (ruis)")
+	noise := fs.Float64("noise", 0.05, "kans dat een waargenomen zet verkeerd is, voor de waarschijnlijkheid")
+	show := fs.Int("show", 20, "aantal getoonde kandidaten")
+	budget := fs.Int64("budget", 200000000, "maximaal aantal doorzochte knopen")
+	fs.Parse(args)
+
+	if *side != 1 && *side != 2 {
+		fmt.Println("Ongeldige kant, moet 1 of 2 zijn.")
+		return
+	}
+	var games []observedGame
+	for _, text := range strings.Split(*gamesText, ",") {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		game, err := parseObservedGame(text)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		games = append(games, game)
+	}
+	if *recordsPath != "" {
+		file, err := os.Open(*recordsPath)
+		if err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *recordsPath, err)
+			return
+		}
+		records, err := readGameRecords(file)
+		file.Close()
+		if err != nil {
+			fmt.Printf("Fout in %s: %v\n", *recordsPath, err)
+			return
+		}
+		for _, r := range records {
+			var game observedGame
+			for i, pair := range r.moves {
+				game.ours[i], game.theirs[i] = pair[2-*side], pair[*side-1]
+			}
+			game.length = len(r.moves)
+			games = append(games, game)
+		}
+	}
+	if len(games) == 0 {
+		fmt.Println("Geen spellen opgegeven. Gestopt.")
+		return
+	}
+	if *maxErrors < 0 || *noise <= 0 || *noise >= 1 {
+		fmt.Println("Ongeldige -errors of -noise.")
+		return
+	}
+
+	digits := classicDepthDigits
+	if *ownDigits {
+		digits += ownDepthDigits
+	}
+	ci := &codeInference{digits: digits, maxErrors: *maxErrors, keep: *show, budget: *budget}
+	for k := 1; k <= len(games); k++ {
+		ci.games = games[:k]
+		// Na de laatste waargenomen zet doet de rest van de code er niet meer toe
+		if !ci.run(ci.lastObserved()) {
+			fmt.Printf("Na spel %d: zoekbudget van %d knopen overschreden, gestopt.\n", k, *budget)
+			return
+		}
+		parts := make([]string, len(ci.counts))
+		for m, count := range ci.counts {
+			parts[m] = fmt.Sprintf("%d met %d fouten", count, m)
+		}
+		fmt.Printf("Na spel %d: %s\n", k, strings.Join(parts, ", "))
+	}
+
+	// Elke fout maakt een code r keer minder waarschijnlijk dan een exacte verklaring
+	ratio := *noise / (4 * (1 - *noise))
+	total := 0.0
+	for m, count := range ci.counts {
+		total += float64(count) * math.Pow(ratio, float64(m))
+	}
+	if total == 0 {
+		fmt.Println("Geen enkele code verklaart de waarnemingen.")
+		return
+	}
+	fmt.Println("Meest waarschijnlijke codes ('?' = vrij, kans per code):")
+	shown := 0
+	for m := range ci.found {
+		for _, code := range ci.found[m] {
+			if shown == *show {
+				break
+			}
+			fmt.Printf("%s  x%d  fouten %d  kans %.4g\n", code.pattern, code.count, m, math.Pow(ratio, float64(m))/total)
+			shown++
+		}
+	}
+}
+
+// **runAdvise** geeft tijdens een echt spel na elke beurt een aanbevolen zet: de gespeelde zetten bepalen welke
+// engines van de tegenstander nog mogelijk zijn, elk voorspelt zijn volgende zet, en de aanbeveling is de
+// beschikbare zet met de beste verwachte rondeuitslag
+func runAdvise(args []string) {
+	fs := flag.NewFlagSet("advise", flag.ExitOnError)
+	poolPath := fs.String("pool", "", "pool met mogelijke tegenstanders (naam:gewicht:code), leeg = geen")
+	depthWeight := fs.Float64("depthweight", 1, "totaal gewicht van alle diepte-codes samen (0 = niet meenemen)")
+	ownDigits := fs.Bool("own", false, "ook eigen-relatieve dieptes (a-d) overwegen")
+	noise := fs.Float64("noise", 0, "gewichtsfactor per zet die een pool-engine niet verklaart (0 = valt af)")
+	history := fs.String("history", "", "al gespeelde zetten als ONZE/HUNNE, bijv. WD/VA")
+	show := fs.Int("show", 5, "aantal getoonde meest waarschijnlijke engines")
+	budget := fs.Int64("budget", 50000000, "maximaal aantal doorzochte knopen voor de diepte-codes")
+	fs.Parse(args)
+
+	var pool []string
+	var weights []float64
+	if *poolPath != "" {
+		var err error
+		if pool, weights, err = readWeightedPool(*poolPath); err != nil {
+			fmt.Printf("Fout bij het lezen van %s: %v\n", *poolPath, err)
+			return
+		}
+	}
+	if len(pool) == 0 && *depthWeight <= 0 {
+		fmt.Println("Geen mogelijke tegenstanders: geef een pool of een positief -depthweight.")
+		return
+	}
+	digits := classicDepthDigits
+	if *ownDigits {
+		digits += ownDepthDigits
+	}
+
+	var ours, theirs [13]byte
+	us := Player{available: [5]int{3, 3, 3, 3, 1}}
+	them := Player{available: [5]int{3, 3, 3, 3, 1}}
+	t := 0
+	// record legt één gespeelde beurt vast; ongeldige zetten of een lege voorraad aan onze kant worden geweigerd
+	record := func(ourMove, theirMove byte) bool {
+		if !isMoveLetter(ourMove) || !isMoveLetter(theirMove) {
+			fmt.Println("Ongeldige zet, gebruik W, V, A, L of D.")
+			return false
+		}
+		if us.available[moveToIndexArray[ourMove]] == 0 {
+			fmt.Printf("Je hebt geen %c meer over.\n", ourMove)
+			return false
+		}
+		if them.available[moveToIndexArray[theirMove]] == 0 {
+			fmt.Printf("De tegenstander heeft geen %c meer over.\n", theirMove)
+			return false
+		}
+		ours[t], theirs[t] = ourMove, theirMove
+		playMove(&us, ourMove)
+		playMove(&them, theirMove)
+		switch determineWinner(ourMove, theirMove) {
+		case 1:
+			us.score++
+		case 2:
+			them.score++
+		}
+		t++
+		return true
+	}
+	if *history != "" {
+		game, err := parseObservedGame(*history)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for i := 0; i < game.length; i++ {
+			if game.theirs[i] == 0 || !record(game.ours[i], game.theirs[i]) {
+				fmt.Printf("Ongeldige geschiedenis bij beurt %d.\n", i+1)
+				return
+			}
+		}
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for t < 13 {
+		belief, complete := opponentBelief(&ours, &theirs, t, pool, weights, *noise, *depthWeight, digits, *budget)
+		if !complete {
+			fmt.Println("Zoekbudget voor diepte-codes overschreden; alleen de pool telt mee.")
+		}
+		total := 0.0
+		var dist [5]float64
+		for _, entry := range belief {
+			total += entry.weight
+			if entry.next != 0 {
+				dist[moveToIndexArray[entry.next]] += entry.weight
+			}
+		}
+		fmt.Printf("\nBeurt %d, stand %d-%d, jouw voorraad W%d V%d A%d L%d D%d\n", t+1, us.score, them.score, us.available[0], us.available[1], us.available[2], us.available[3], us.available[4])
+		if total == 0 {
+			fmt.Println("Geen enkele engine verklaart de gespeelde zetten; geen voorspelling mogelijk.")
+		} else {
+			sort.SliceStable(belief, func(i, j int) bool { return belief[i].weight > belief[j].weight })
+			fmt.Printf("%d mogelijke engines, meest waarschijnlijk:\n", len(belief))
+			for k := 0; k < len(belief) && k < *show; k++ {
+				fmt.Printf("  %s  %.1f%%  speelt %c\n", belief[k].label, 100*belief[k].weight/total, belief[k].next)
+			}
+			fmt.Print("Verwachte zet tegenstander:")
+			for idx, move := range depthToElement {
+				fmt.Printf(" %c %.0f%%", move, 100*dist[idx]/total)
+			}
+			fmt.Println()
+
+			best, bestValue, bestWin := byte(0), math.Inf(-1), 0.0
+			for idx, move := range depthToElement {
+				if us.available[idx] == 0 || (t == 12 && move != getLastElement(&us.available)) {
+					continue
+				}
+				var win, loss float64
+				for opp, theirMove := range depthToElement {
+					switch determineWinner(move, theirMove) {
+					case 1:
+						win += dist[opp] / total
+					case 2:
+						loss += dist[opp] / total
+					}
+				}
+				fmt.Printf("  %c: winst %.0f%%, gelijk %.0f%%, verlies %.0f%%\n", move, 100*win, 100*(1-win-loss), 100*loss)
+				// Bij gelijke verwachting: meer winstkans, dan het element waarvan we het meeste over hebben
+				if value := win - loss; value > bestValue+1e-9 || (value > bestValue-1e-9 && (win > bestWin+1e-9 || (win > bestWin-1e-9 && us.available[idx] > us.available[moveToIndexArray[best]]))) {
+					best, bestValue, bestWin = move, value, win
+				}
+			}
+			fmt.Printf("Aanbevolen zet: %c\n", best)
+		}
+
+		fmt.Print("Gespeelde zetten (jouw zet en die van de tegenstander, bijv. 'W V', leeg = stoppen): ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return
+		}
+		fields := strings.Fields(strings.ToUpper(scanner.Text()))
+		if len(fields) == 0 {
+			return
+		}
+		if len(fields) != 2 || len(fields[0]) != 1 || len(fields[1]) != 1 {
+			fmt.Println("Geef twee zetten, gescheiden door een spatie.")
+			continue
+		}
+		record(fields[0][0], fields[1][0])
+	}
+	fmt.Printf("\nEinde van het spel: %d-%d\n", us.score, them.score)
+}
+
+// **runPlay** laat een mens in de terminal tegen een engine spelen; het spel wordt als verslag bewaard
+func runPlay(args []string) {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	engineCode := fs.String("engine", "", "engine code van de tegenstander (leeg = de beste uit -engines)")
+	enginesPath := fs.String("engines", "top_10000_engines.txt", "resultatenbestand waaruit de beste engine gekozen wordt")
+	name := fs.String("name", "mens", "jouw naam in het verslag")
+	second := fs.Bool("second", false, "als speler 2 spelen (de engine is speler 1)")
+	outPath := fs.String("out", "played_games.txt", "bestand waaraan het verslag wordt toegevoegd (leeg = niet bewaren)")
+	fs.Parse(args)
+
+	engine := parseEngineCode(*engineCode)
+	if engine == "" {
+		engines, err := readEngineFile(*enginesPath, 1)
+		if err != nil || len(engines) == 0 {
+			fmt.Printf("Geen engine opgegeven en geen engine gevonden in %s.\n", *enginesPath)
+			return
+		}
+		engine = engines[0]
+	}
+	if !isValidEngineCode(engine) {
+		fmt.Printf("Ongeldige engine code '%s'.\n", engine)
+		return
+	}
+	// Een eindspel-suffix lost op tegen een bekende tegenstander; tegen een mens speelt de basiscode
+	base, _ := splitEndgame(engine)
+
+	human := Player{available: [5]int{3, 3, 3, 3, 1}}
+	bot := Player{available: [5]int{3, 3, 3, 3, 1}}
+	fmt.Printf("Je speelt tegen %s. W verslaat V, V verslaat A, A verslaat L, L verslaat W; D speelt altijd gelijk.\n", engine)
+	scanner := bufio.NewScanner(os.Stdin)
+	for i := 0; i < 13; i++ {
+		// De engine kiest eerst, zonder onze zet te kennen
+		botMove := engineMove(base, i, &bot, &human)
+		if botMove == 0 {
+			fmt.Println("De engine heeft geen geldige zet meer, spel afgebroken.")
+			return
+		}
+		fmt.Printf("\nBeurt %d, jouw voorraad W%d V%d A%d L%d D%d\n", i+1, human.available[0], human.available[1], human.available[2], human.available[3], human.available[4])
+		var move byte
+		if i == 12 {
+			move = getLastElement(&human.available)
+			fmt.Printf("Laatste beurt, je speelt je laatste element: %c\n", move)
+		}
+		for move == 0 {
+			fmt.Print("Jouw zet (W, V, A, L, D): ")
+			if !scanner.Scan() {
+				fmt.Println("\nSpel afgebroken.")
+				return
+			}
+			input := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+			switch {
+			case len(input) != 1 || !isMoveLetter(input[0]):
+				fmt.Println("Ongeldige zet, gebruik W, V, A, L of D.")
+			case human.available[moveToIndexArray[input[0]]] == 0:
+				fmt.Printf("Je hebt geen %c meer over.\n", input[0])
+			default:
+				move = input[0]
+			}
+		}
+		playMove(&human, move)
+		playMove(&bot, botMove)
+		result := "gelijk"
+		switch determineWinner(move, botMove) {
+		case 1:
+			human.score++
+			result = "jij wint de ronde"
+		case 2:
+			bot.score++
+			result = "de engine wint de ronde"
+		}
+		fmt.Printf("Jij %c, engine %c: %s. Stand %d-%d\n", move, botMove, result, human.score, bot.score)
+	}
+
+	switch {
+	case human.score > bot.score:
+		fmt.Printf("\nGewonnen met %d-%d!\n", human.score, bot.score)
+	case human.score < bot.score:
+		fmt.Printf("\nVerloren met %d-%d.\n", human.score, bot.score)
+	default:
+		fmt.Printf("\nGelijkspel, %d-%d.\n", human.score, bot.score)
+	}
+	if *outPath == "" {
+		return
+	}
+	r := newGameRecord(*name, engine, human, bot)
+	r.removeHeader("Engine1")
+	if *second {
+		r = newGameRecord(engine, *name, bot, human)
+		r.removeHeader("Engine2")
+	}
+	file, err := os.OpenFile(*outPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Printf("Fout bij het openen van bestand: %v\n", err)
+		return
+	}
+	defer file.Close()
+	if err := writeGameRecord(file, r); err != nil {
+		fmt.Printf("Fout bij het schrijven: %v\n", err)
+		return
+	}
+	fmt.Printf("Verslag toegevoegd aan %s\n", *outPath)
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	for {
+		fmt.Println("Voer engine codes in (één per regel, '.' om te stoppen):")
+		scanner := bufio.NewScanner(os.Stdin)
+		inputEngines := readEngines(scanner)
+		if err := scanner.Err(); err != nil {
+			fmt.Printf("Fout bij het lezen van invoer: %v\n", err)
+			continue
 		}
 
 		if len(inputEngines) == 0 {
@@ -455,11 +5076,12 @@ func main() {
 		var startDepth string
 		var maxMemoryMB int
 
-		fmt.Println("Voer de startdepth in (leeg voor alle combinaties, bijv. '51'): ")
+		fmt.Println("Voer de startdepth in (leeg voor alle combinaties, bijv. '51', opening 'WVA', '**' voor alle openingen of 'g' voor terugblik-codes): ")
 		fmt.Scanln(&startDepth)
 
-		if len(startDepth) > 12 || (startDepth != "" && strings.ContainsAny(startDepth, "0")) {
-			fmt.Println("Ongeldige startdepth. Moet <= 12 chiffres zijn, eerste positie 1-5, rest 1-9.")
+		lookback := strings.HasPrefix(startDepth, "g")
+		if !lookback && (len(startDepth) > 12 || (startDepth != "" && strings.ContainsAny(startDepth, "0"))) {
+			fmt.Println("Ongeldige startdepth. Moet <= 12 tekens zijn: optionele opening (W, V, A, L, D of '*'), dan chiffres; eerste chiffre 1-5, rest 1-9 of a-d.")
 			continue
 		}
 
@@ -473,7 +5095,61 @@ func main() {
 			fmt.Println("Ongeldige invoer, defaulting naar 64.000 MB.")
 		}
 
-		generatedEngines := generateEngines(startDepth)
+		fmt.Printf("Voer de fallback-policies in (subset van '%s', leeg = alleen standaard): ", fallbackPolicyCodes)
+		var policyInput string
+		fmt.Scanln(&policyInput)
+		policies := ""
+		for _, policy := range policyInput {
+			if strings.ContainsRune(fallbackPolicyCodes, policy) && !strings.ContainsRune(policies, policy) {
+				policies += string(policy)
+			}
+		}
+
+		var generatedEngines []string
+		if lookback {
+			maxBack, budget := 2, int64(50000000)
+			fmt.Printf("Voer de maximale terugblik k in (1-%d, default %d): ", maxLookback, maxBack)
+			var backInput string
+			fmt.Scanln(&backInput)
+			if backInput != "" {
+				if n, err := fmt.Sscanf(backInput, "%d", &maxBack); err != nil || n != 1 || maxBack < 1 || maxBack > maxLookback {
+					maxBack = 2
+					fmt.Println("Ongeldige invoer, defaulting naar 2.")
+				}
+			}
+			fmt.Print("Eigen vorige zet als referentie meenemen? (j/N): ")
+			var ownInput string
+			fmt.Scanln(&ownInput)
+			includeOwn := strings.EqualFold(ownInput, "j")
+			fmt.Printf("Voer het maximale aantal engines in (default %d): ", budget)
+			var budgetInput string
+			fmt.Scanln(&budgetInput)
+			if budgetInput != "" {
+				if n, err := fmt.Sscanf(budgetInput, "%d", &budget); err != nil || n != 1 || budget < 1 {
+					budget = 50000000
+					fmt.Println("Ongeldige invoer, defaulting naar 50.000.000 engines.")
+				}
+			}
+			var count int64
+			generatedEngines, count = generateLookbackEngines(startDepth, maxBack, includeOwn, policies, budget)
+			if count == 0 {
+				fmt.Println("Ongeldige terugblik-prefix. Gebruik 'g' gevolgd door paren diepte (1-5) en referentie (1-k of 'e').")
+				continue
+			}
+			if count > budget {
+				fmt.Printf("De familie telt %d engines, meer dan het budget van %d. Maak de prefix langer of k kleiner.\n", count, budget)
+				continue
+			}
+		} else {
+			fmt.Print("Eigen-relatieve dieptes (a-d) meenemen? (j/N): ")
+			var selfInput string
+			fmt.Scanln(&selfInput)
+			digits := classicDepthDigits
+			if strings.EqualFold(selfInput, "j") {
+				digits += ownDepthDigits
+			}
+			generatedEngines = generateEngines(startDepth, digits, policies)
+		}
 
 		const bytesPerResult = 24
 		maxBufferSize := (maxMemoryMB * 1024 * 1024) / bytesPerResult
@@ -484,15 +5160,6 @@ func main() {
 			maxBufferSize = 10000
 		}
 
-		totalEngines := len(generatedEngines)
-		numInputEngines := len(inputEngines)
-		totalComparisons = int64(totalEngines) * int64(numInputEngines)
-
-		top10000Chan := make(chan engineResult, 1000000)
-		var wg sync.WaitGroup
-		progressComparisons = 0
-		startTime = time.Now()
-
 		defaultThreads := runtime.NumCPU()
 		fmt.Printf("Voer het aantal threads in (default %d): ", defaultThreads)
 		var threadsInput string
@@ -504,58 +5171,12 @@ func main() {
 				fmt.Printf("Ongeldige invoer, defaulting naar %d threads.\n", defaultThreads)
 			}
 		}
-		enginesPerThread := (totalEngines + numThreads - 1) / numThreads
-
-		for i := 0; i < numThreads; i++ {
-			start := i * enginesPerThread
-			end := start + enginesPerThread
-			if end > totalEngines {
-				end = totalEngines
-			}
-			wg.Add(1)
-			go func(threadStart, threadEnd int) {
-				defer wg.Done()
-				batch := generatedEngines[threadStart:threadEnd]
-				evaluateBatch(batch, inputEngines, top10000Chan, &progressComparisons)
-			}(start, end)
-		}
-
-		go func() {
-			wg.Wait()
-			close(top10000Chan)
-		}()
-
-		file, err := os.Create("top_10000_engines.txt")
-		if err != nil {
-			fmt.Printf("Fout bij het openen van bestand: %v\n", err)
-			return
-		}
-		defer file.Close()
-
-		top10000 := &minHeap{}
-		heap.Init(top10000)
-		maxSize := 10000
-		for result := range top10000Chan {
-			if top10000.Len() < maxSize {
-				heap.Push(top10000, result)
-			} else if result.score > (*top10000)[0].score {
-				heap.Pop(top10000)
-				heap.Push(top10000, result)
-			}
-		}
 
-		if top10000.Len() > 0 {
-			results := make([]engineResult, 0, maxSize)
-			for top10000.Len() > 0 {
-				results = append(results, heap.Pop(top10000).(engineResult))
-			}
-			for i := len(results) - 1; i >= 0; i-- {
-				result := results[i]
-				_, err := file.WriteString(fmt.Sprintf("%s (score: %d)\n", result.engine, result.score))
-				if err != nil {
-					fmt.Printf("Fout bij het schrijven: %v\n", err)
-					break
-				}
+		results := evaluateEngines(generatedEngines, inputEngines, numThreads)
+		if len(results) > 0 {
+			if err := writeResults("top_10000_engines.txt", results); err != nil {
+				fmt.Printf("Fout bij het schrijven: %v\n", err)
+				return
 			}
 			fmt.Printf("Top 10.000 engines opgeslagen uit %d matches.\n", totalComparisons)
 		} else {
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// **TestGameRecordRoundTrip** controleert dat schrijven en weer inlezen van een verslag niets verandert
+func TestGameRecordRoundTrip(t *testing.T) {
+	var records []gameRecord
+	for _, pair := range [][2]string{
+		{"152333344442", "151111111141"},
+		{"WWWVVVAAALLLD", "152333344442"},
+		{"215133333443", "LLLAAAVVVWWWD"},
+	} {
+		p1, p2, ok := playGame(pair[0], pair[1], false)
+		if !ok {
+			t.Fatalf("spel %s tegen %s is ongeldig", pair[0], pair[1])
+		}
+		records = append(records, newGameRecord(pair[0], pair[1], p1, p2))
+	}
+	records[0].setHeader("Opmerking", `met "aanhalingstekens" en een \ teken`)
+
+	var first strings.Builder
+	for _, r := range records {
+		if err := writeGameRecord(&first, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read, err := readGameRecords(strings.NewReader(first.String()))
+	if err != nil {
+		t.Fatalf("verslagen niet leesbaar: %v\n%s", err, first.String())
+	}
+	if !reflect.DeepEqual(read, records) {
+		t.Fatalf("ingelezen verslagen wijken af:\n%+v\nverwacht:\n%+v", read, records)
+	}
+
+	var second strings.Builder
+	for _, r := range read {
+		writeGameRecord(&second, r)
+	}
+	if second.String() != first.String() {
+		t.Fatalf("opnieuw geschreven tekst wijkt af:\n%s\nverwacht:\n%s", second.String(), first.String())
+	}
+}
+
+// **TestGameRecordRejects** controleert dat ongeldige verslagen een fout geven
+func TestGameRecordRejects(t *testing.T) {
+	// moves speelt aan beide kanten dezelfde geldige lijn, na 13 zetten opnieuw van voren af aan
+	moves := func(n int) string {
+		const line = "WVALWVALWVALD"
+		var b strings.Builder
+		for i := 1; i <= n; i++ {
+			m := line[(i-1)%len(line)]
+			fmt.Fprintf(&b, "%d. %c %c\n", i, m, m)
+		}
+		return b.String()
+	}
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"uitslag klopt niet", "[Speler1 \"a\"]\n\n1. W V\n0-0\n"},
+		{"uitslag voor de verkeerde kant", "1. W V\n2. V A\n0-2\n"},
+		{"meer dan 13 zetten", moves(14) + "0-0\n"},
+		{"onbekend element", "1. W X\n0-0\n"},
+		{"kleine letter", "1. w v\n1-0\n"},
+		{"ontbrekende uitslag", "1. W V\n"},
+		{"meer W dan de voorraad", "1. W W\n2. W W\n3. W W\n4. W V\n0-0\n"},
+		{"tweede D", "1. D W\n2. D V\n0-20\n"},
+	}
+	for _, tt := range tests {
+		if _, err := readGameRecords(strings.NewReader(tt.input)); err == nil {
+			t.Errorf("%s: geen fout voor\n%s", tt.name, tt.input)
+		}
+	}
+}
+
+// **TestOptimalCodeSuffixes** controleert dat optimale engines geen suffix aannemen en dat spelen niet vastloopt
+func TestOptimalCodeSuffixes(t *testing.T) {
+	if !isValidEngineCode("o1") {
+		t.Error("o1 moet geldig zijn")
+	}
+	for _, engine := range []string{"o1#3", "o1~D", "o1!w", "o1!w~D", "o1~D!w"} {
+		if isValidEngineCode(engine) {
+			t.Errorf("%s mag niet geldig zijn", engine)
+		}
+	}
+	// Ook als een ongeldige code toch gespeeld wordt, mag engineMove niet in stepTarget belanden
+	for _, pair := range [][2]string{{"o1#3", "152333344442"}, {"152333344442", "o1#3"}} {
+		if _, _, ok := playGame(pair[0], pair[1], false); !ok {
+			t.Errorf("%s tegen %s is ongeldig gespeeld", pair[0], pair[1])
+		}
+	}
+}
+
+// **TestDepthCodeFives** controleert dat een dieptecode hoogstens één '5' of D bevat
+func TestDepthCodeFives(t *testing.T) {
+	tests := []struct {
+		engine string
+		valid  bool
+	}{
+		{"152333344442", true},
+		{"WD2333344442", true},
+		{"155333344442", false},
+		{"152333345442", false},
+		{"D52333344442", false},
+		{"WVD233334445", false},
+	}
+	for _, tt := range tests {
+		if got := isValidDepthCode(tt.engine); got != tt.valid {
+			t.Errorf("isValidDepthCode(%q) = %v, verwacht %v", tt.engine, got, tt.valid)
+		}
+	}
+}